@@ -0,0 +1,289 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/judgment"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+
+	"go.uber.org/zap"
+)
+
+const maxSearchSize = 100
+
+// SearchRequest is the body of POST /api/v1/search, GET requests use the same fields as query parameters.
+type SearchRequest struct {
+	Title              string   `json:"title"`
+	Description        string   `json:"description"`
+	Source             string   `json:"source"`
+	Sources            []string `json:"sources"`
+	OnlyPublic         bool     `json:"onlyPublic"`
+	ExcludedDocumentId string   `json:"excludedDocumentId"`
+	MinScore           float64  `json:"minScore"`
+	Size               int      `json:"size"`
+	From               int      `json:"from"`
+	Status             string   `json:"status"`
+	Labels             []string `json:"labels"`
+	Explain            bool     `json:"explain"`
+}
+
+func (r SearchRequest) filter() search.SearchFilter {
+	return search.SearchFilter{
+		ExcludedDocumentId: r.ExcludedDocumentId,
+		Source:             r.Source,
+		Sources:            r.Sources,
+		OnlyPublic:         r.OnlyPublic,
+		MinScore:           r.MinScore,
+		Size:               r.Size,
+		From:               r.From,
+		Status:             r.Status,
+		Labels:             r.Labels,
+		Explain:            r.Explain,
+	}
+}
+
+func (r SearchRequest) validate() error {
+	if r.Size < 0 || r.Size > maxSearchSize {
+		return fmt.Errorf("size must be between 0 and %d", maxSearchSize)
+	}
+
+	if r.From < 0 {
+		return errors.New("from must not be negative")
+	}
+
+	if r.MinScore < 0 {
+		return errors.New("minScore must not be negative")
+	}
+
+	return nil
+}
+
+func registerApiHandlers(mux *http.ServeMux, cfg config.Config, logger *zap.SugaredLogger, middleware func(http.Handler) http.Handler) {
+	mux.Handle("/api/v1/search", middleware(requireApiKey(cfg, logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request SearchRequest
+		var err error
+
+		switch r.Method {
+		case http.MethodGet:
+			request, err = searchRequestFromQuery(r)
+		case http.MethodPost:
+			err = json.NewDecoder(r.Body).Decode(&request)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			JSONError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed, logger)
+			return
+		}
+
+		if err != nil {
+			JSONError(w, fmt.Errorf("invalid search request: %w", err), http.StatusBadRequest, logger)
+			return
+		}
+
+		if request.Title == "" && request.Description == "" {
+			JSONError(w, errors.New("title or description is required"), http.StatusBadRequest, logger)
+			return
+		}
+
+		if err := request.validate(); err != nil {
+			JSONError(w, err, http.StatusBadRequest, logger)
+			return
+		}
+
+		description := request.Description
+		if description == "" {
+			description = request.Title
+		}
+
+		title := request.Title
+		if title == "" {
+			title = request.Description
+		}
+
+		result, err := search.Search(title, description, request.filter(), cfg, r.Context())
+		if err != nil {
+			logger.Errorf("API search failed: %s", err)
+			JSONError(w, errors.New("search failed"), http.StatusInternalServerError, logger)
+			return
+		}
+
+		JSONResp(w, result, logger)
+	}))))
+
+	mux.Handle("/api/v1/documents/", middleware(requireApiKey(cfg, logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, action, found := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/documents/"), "/")
+		if !found || id == "" || action != "similar" {
+			JSONError(w, errors.New("not found"), http.StatusNotFound, logger)
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			JSONError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed, logger)
+			return
+		}
+
+		request, err := searchRequestFromQuery(r)
+		if err != nil {
+			JSONError(w, fmt.Errorf("invalid search request: %w", err), http.StatusBadRequest, logger)
+			return
+		}
+
+		// The document itself would always be the best match.
+		request.ExcludedDocumentId = id
+
+		if err := request.validate(); err != nil {
+			JSONError(w, err, http.StatusBadRequest, logger)
+			return
+		}
+
+		result, err := search.SearchId(id, request.filter(), cfg, r.Context())
+		if errors.Is(err, search.ErrDocumentNotFound) {
+			JSONError(w, err, http.StatusNotFound, logger)
+			return
+		}
+
+		if err != nil {
+			logger.Errorf("API similar search for %s failed: %s", id, err)
+			JSONError(w, errors.New("search failed"), http.StatusInternalServerError, logger)
+			return
+		}
+
+		JSONResp(w, result, logger)
+	}))))
+}
+
+// registerJudgmentHandlers records "duplicate" / "not duplicate" decisions made in the web UI.
+func registerJudgmentHandlers(mux *http.ServeMux, cfg config.Config, logger *zap.SugaredLogger, middleware func(http.Handler) http.Handler) {
+	mux.Handle("/api/v1/judgments", middleware(requireApiKey(cfg, logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			JSONError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed, logger)
+			return
+		}
+
+		var request judgment.Judgment
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			JSONError(w, fmt.Errorf("invalid judgment: %w", err), http.StatusBadRequest, logger)
+			return
+		}
+
+		request.Origin = "ui"
+		request.Date = 0
+
+		if err := request.Validate(); err != nil {
+			JSONError(w, err, http.StatusBadRequest, logger)
+			return
+		}
+
+		if err := judgment.Record(request, cfg, r.Context()); err != nil {
+			logger.Errorf("Recording judgment failed: %s", err)
+			JSONError(w, errors.New("recording judgment failed"), http.StatusInternalServerError, logger)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))))
+}
+
+// requireApiKey only lets requests through which send one of the configured API_KEYS, either
+// as "Authorization: Bearer <key>" or as "X-Api-Key: <key>". Without configured keys the API is disabled.
+func requireApiKey(cfg config.Config, logger *zap.SugaredLogger, next http.Handler) http.Handler {
+	return requireKey(cfg.ApiKeys, logger, next)
+}
+
+// requireAdminApiKey only accepts the keys of ADMIN_API_KEYS, keys for the search API cannot run jobs.
+func requireAdminApiKey(cfg config.Config, logger *zap.SugaredLogger, next http.Handler) http.Handler {
+	return requireKey(cfg.AdminApiKeys, logger, next)
+}
+
+func requireKey(apiKeys []string, logger *zap.SugaredLogger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-Api-Key")
+		if bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+			key = bearer
+		}
+
+		if !isValidApiKey(key, apiKeys) {
+			JSONError(w, errors.New("invalid or missing API key"), http.StatusUnauthorized, logger)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isValidApiKey(key string, apiKeys []string) bool {
+	if key == "" {
+		return false
+	}
+
+	valid := false
+	for _, apiKey := range apiKeys {
+		if apiKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+			valid = true
+		}
+	}
+
+	return valid
+}
+
+func searchRequestFromQuery(r *http.Request) (SearchRequest, error) {
+	query := r.URL.Query()
+
+	request := SearchRequest{
+		Title:              query.Get("title"),
+		Description:        query.Get("description"),
+		Source:             query.Get("source"),
+		ExcludedDocumentId: query.Get("excludedDocumentId"),
+		Status:             query.Get("status"),
+	}
+
+	if sources := query.Get("sources"); sources != "" {
+		request.Sources = strings.Split(sources, ",")
+	}
+
+	if labels := query.Get("labels"); labels != "" {
+		request.Labels = strings.Split(labels, ",")
+	}
+
+	var err error
+
+	if value := query.Get("explain"); value != "" {
+		if request.Explain, err = strconv.ParseBool(value); err != nil {
+			return request, fmt.Errorf("invalid explain: %s", value)
+		}
+	}
+
+	if value := query.Get("onlyPublic"); value != "" {
+		if request.OnlyPublic, err = strconv.ParseBool(value); err != nil {
+			return request, fmt.Errorf("invalid onlyPublic: %s", value)
+		}
+	}
+
+	if value := query.Get("minScore"); value != "" {
+		if request.MinScore, err = strconv.ParseFloat(value, 64); err != nil {
+			return request, fmt.Errorf("invalid minScore: %s", value)
+		}
+	}
+
+	if value := query.Get("size"); value != "" {
+		if request.Size, err = strconv.Atoi(value); err != nil {
+			return request, fmt.Errorf("invalid size: %s", value)
+		}
+	}
+
+	if value := query.Get("from"); value != "" {
+		if request.From, err = strconv.Atoi(value); err != nil {
+			return request, fmt.Errorf("invalid from: %s", value)
+		}
+	}
+
+	return request, nil
+}
@@ -0,0 +1,161 @@
+// Package client is a Go client for the HTTP API described in api/openapi.json. It is written by hand, the
+// tests check its requests against the specification.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/judgment"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+)
+
+type Client struct {
+	baseUrl    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// SearchRequest mirrors the SearchRequest schema of the specification.
+type SearchRequest struct {
+	Title              string   `json:"title,omitempty"`
+	Description        string   `json:"description,omitempty"`
+	Source             string   `json:"source,omitempty"`
+	Sources            []string `json:"sources,omitempty"`
+	OnlyPublic         bool     `json:"onlyPublic,omitempty"`
+	ExcludedDocumentId string   `json:"excludedDocumentId,omitempty"`
+	Status             string   `json:"status,omitempty"`
+	Labels             []string `json:"labels,omitempty"`
+	MinScore           float64  `json:"minScore,omitempty"`
+	Size               int      `json:"size,omitempty"`
+	From               int      `json:"from,omitempty"`
+	Explain            bool     `json:"explain,omitempty"`
+}
+
+// Error is returned for all responses with an Error body.
+type Error struct {
+	StatusCode int
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+func New(baseUrl string, apiKey string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{baseUrl: strings.TrimSuffix(baseUrl, "/"), apiKey: apiKey, httpClient: httpClient}
+}
+
+func (c *Client) Search(ctx context.Context, request SearchRequest) (*search.SearchResponse, error) {
+	body, _ := json.Marshal(request)
+
+	var response search.SearchResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/search", body, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// Similar searches for documents similar to an indexed document, Title, Description and
+// ExcludedDocumentId of the request are ignored.
+func (c *Client) Similar(ctx context.Context, id string, request SearchRequest) (*search.SearchResponse, error) {
+	query := url.Values{}
+	if request.Source != "" {
+		query.Set("source", request.Source)
+	}
+	if len(request.Sources) > 0 {
+		query.Set("sources", strings.Join(request.Sources, ","))
+	}
+	if request.OnlyPublic {
+		query.Set("onlyPublic", "true")
+	}
+	if request.Status != "" {
+		query.Set("status", request.Status)
+	}
+	if len(request.Labels) > 0 {
+		query.Set("labels", strings.Join(request.Labels, ","))
+	}
+	if request.MinScore > 0 {
+		query.Set("minScore", fmt.Sprint(request.MinScore))
+	}
+	if request.Size > 0 {
+		query.Set("size", fmt.Sprint(request.Size))
+	}
+	if request.From > 0 {
+		query.Set("from", fmt.Sprint(request.From))
+	}
+	if request.Explain {
+		query.Set("explain", "true")
+	}
+
+	path := "/api/v1/documents/" + url.PathEscape(id) + "/similar"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var response search.SearchResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (c *Client) RecordJudgment(ctx context.Context, judgment judgment.Judgment) error {
+	body, _ := json.Marshal(judgment)
+
+	return c.do(ctx, http.MethodPost, "/api/v1/judgments", body, nil)
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body []byte, target any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.baseUrl+path, reader)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("X-Api-Key", c.apiKey)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		apiError := &Error{StatusCode: response.StatusCode}
+		_ = json.Unmarshal(responseBody, apiError)
+
+		return apiError
+	}
+
+	if target == nil {
+		return nil
+	}
+
+	return json.Unmarshal(responseBody, target)
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/shopwarelabs/jira-issue-bot/api"
+	"github.com/shopwarelabs/jira-issue-bot/domain/judgment"
+)
+
+// specServer validates every request of the client against api/openapi.json, the responses are canned.
+func specServer(t *testing.T) (*openapi3.T, string, *[]string) {
+	t.Helper()
+
+	doc, err := openapi3.NewLoader().LoadFromData(api.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lock sync.Mutex
+	var operations []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := router.FindRoute(r)
+		if err != nil {
+			t.Errorf("%s %s is not documented: %s", r.Method, r.URL, err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+		}
+		if err := openapi3filter.ValidateRequest(context.Background(), input); err != nil {
+			t.Errorf("%s does not match the specification: %s", route.Operation.OperationID, err)
+		}
+
+		// Unknown query parameters are valid for the validator, but would be ignored by the server.
+		for name := range r.URL.Query() {
+			if route.Operation.Parameters.GetByInAndName(openapi3.ParameterInQuery, name) == nil {
+				t.Errorf("query parameter %s of %s is not documented", name, route.Operation.OperationID)
+			}
+		}
+
+		lock.Lock()
+		operations = append(operations, route.Operation.OperationID)
+		lock.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case pathParams["id"] == "GH-404":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": true, "message": "document not found"}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/judgments"):
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.Write([]byte(`{"hits": {"total": {"value": 0}, "hits": []}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return doc, server.URL, &operations
+}
+
+// TestClientMatchesSpecification sends every request the client supports, with all options set, to a server
+// validating them against the specification.
+func TestClientMatchesSpecification(t *testing.T) {
+	_, url, operations := specServer(t)
+	c := New(url, "key", nil)
+	ctx := context.Background()
+
+	request := SearchRequest{
+		Title:              "Paging broken",
+		Description:        "The second page is empty",
+		Source:             "github",
+		Sources:            []string{"github", "jira"},
+		OnlyPublic:         true,
+		ExcludedDocumentId: "GH-1",
+		Status:             "open",
+		Labels:             []string{"bug"},
+		MinScore:           1.5,
+		Size:               5,
+		From:               10,
+		Explain:            true,
+	}
+
+	if _, err := c.Search(ctx, request); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Similar(ctx, "GH-1", request); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.RecordJudgment(ctx, judgment.Judgment{DocumentId: "GH-1", CandidateId: "GH-2", Duplicate: true, Score: 2.1}); err != nil {
+		t.Fatal(err)
+	}
+
+	var apiError *Error
+	if _, err := c.Similar(ctx, "GH-404", SearchRequest{}); !errors.As(err, &apiError) || apiError.StatusCode != http.StatusNotFound || apiError.Message != "document not found" {
+		t.Errorf("expected the Error response to be decoded, got %v", err)
+	}
+
+	if strings.Join(*operations, ",") != "searchPost,similarDocuments,recordJudgment,similarDocuments" {
+		t.Errorf("unexpected operations: %v", *operations)
+	}
+}
+
+// TestSearchRequestMatchesSchema catches renamed or missing properties, the schema allows additional ones.
+func TestSearchRequestMatchesSchema(t *testing.T) {
+	doc, _, _ := specServer(t)
+
+	var documented []string
+	for name := range doc.Components.Schemas["SearchRequest"].Value.Properties {
+		documented = append(documented, name)
+	}
+
+	var fields []string
+	for _, field := range reflect.VisibleFields(reflect.TypeOf(SearchRequest{})) {
+		fields = append(fields, strings.Split(field.Tag.Get("json"), ",")[0])
+	}
+
+	sort.Strings(documented)
+	sort.Strings(fields)
+
+	if strings.Join(fields, ",") != strings.Join(documented, ",") {
+		t.Errorf("expected the fields %v of the SearchRequest schema, got %v", documented, fields)
+	}
+}
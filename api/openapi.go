@@ -0,0 +1,8 @@
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3 specification of the HTTP API, it is served at /api/openapi.json.
+//
+//go:embed openapi.json
+var OpenAPI []byte
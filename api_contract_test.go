@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/api"
+	"github.com/shopwarelabs/jira-issue-bot/api/client"
+	"github.com/shopwarelabs/jira-issue-bot/domain/judgment"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/health"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/scheduler"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/opensearch-project/opensearch-go/v2"
+	"go.uber.org/zap"
+)
+
+const fakeSearchResponse = `{
+	"took": 12,
+	"timed_out": false,
+	"_shards": { "total": 1, "successful": 1, "skipped": 0, "failed": 0 },
+	"hits": {
+		"total": { "value": 1, "relation": "eq" },
+		"max_score": 3.3,
+		"hits": [{
+			"_index": "issues",
+			"_id": "GH-1",
+			"_score": 3.3,
+			"_source": {
+				"title": "Github Issues are fancy",
+				"description": "Fancy that github supports webhook issues",
+				"status": "open",
+				"type": "Issue",
+				"link": "https://github.com/shopware/platform/issues/1",
+				"externalLink": "https://github.com/shopware/platform/issues/1",
+				"fixVersion": ["n/a"],
+				"public": true,
+				"source": "github",
+				"authorName": "author",
+				"authorLink": "https://github.com/author",
+				"dateCreated": 1609459200,
+				"labels": ["bug"]
+			},
+			"_explanation": {
+				"value": 3.3,
+				"description": "sum of:",
+				"details": [
+					{ "value": 1.8, "description": "script score function, computed with script:\"Script{type=inline, lang='painless', idOrCode='_score * 1.8', options={}, params={field=title}}\"", "details": [] },
+					{ "value": 1.5, "description": "script score function, computed with script:\"Script{type=inline, lang='painless', idOrCode='_score * 1.5', options={}, params={field=description}}\"", "details": [] }
+				]
+			}
+		}]
+	}
+}`
+
+// TestApiContract runs requests against the API handlers backed by a fake OpenSearch and
+// validates requests and responses against api/openapi.json, so the JSONResp and JSONError shapes stay stable.
+func TestApiContract(t *testing.T) {
+	router := openAPIRouter(t)
+	mux := contractTestMux(t)
+
+	for _, testCase := range []struct {
+		name            string
+		method          string
+		target          string
+		body            string
+		apiKey          string
+		status          int
+		validateRequest bool
+	}{
+		{name: "search by query", method: http.MethodGet, target: "/api/v1/search?title=fancy&sources=github,jira&explain=true&size=5", apiKey: "key", status: http.StatusOK, validateRequest: true},
+		{name: "search by source", method: http.MethodGet, target: "/api/v1/search?title=fancy&source=github", apiKey: "key", status: http.StatusOK, validateRequest: true},
+		{name: "search by body", method: http.MethodPost, target: "/api/v1/search", body: `{"title": "fancy", "labels": ["bug"], "onlyPublic": true}`, apiKey: "key", status: http.StatusOK, validateRequest: true},
+		{name: "search without api key", method: http.MethodGet, target: "/api/v1/search?title=fancy", status: http.StatusUnauthorized},
+		{name: "search without title", method: http.MethodPost, target: "/api/v1/search", body: `{}`, apiKey: "key", status: http.StatusBadRequest, validateRequest: true},
+		{name: "search with invalid size", method: http.MethodGet, target: "/api/v1/search?title=fancy&size=500", apiKey: "key", status: http.StatusBadRequest},
+		{name: "similar documents", method: http.MethodGet, target: "/api/v1/documents/GH-1/similar?onlyPublic=true", apiKey: "key", status: http.StatusOK, validateRequest: true},
+		{name: "similar documents of unknown document", method: http.MethodGet, target: "/api/v1/documents/GH-404/similar", apiKey: "key", status: http.StatusNotFound, validateRequest: true},
+		{name: "judgment", method: http.MethodPost, target: "/api/v1/judgments", body: `{"documentId": "GH-1", "candidateId": "GH-2", "duplicate": true}`, apiKey: "key", status: http.StatusCreated, validateRequest: true},
+		{name: "health", method: http.MethodGet, target: "/healthz", status: http.StatusOK, validateRequest: true},
+		{name: "readiness", method: http.MethodGet, target: "/readyz", status: http.StatusOK, validateRequest: true},
+		{name: "jobs with search api key", method: http.MethodGet, target: "/api/v1/admin/jobs", apiKey: "key", status: http.StatusUnauthorized},
+		{name: "jobs", method: http.MethodGet, target: "/api/v1/admin/jobs", apiKey: "admin", status: http.StatusOK, validateRequest: true},
+		{name: "run job", method: http.MethodPost, target: "/api/v1/admin/jobs/github-sync/run", apiKey: "admin", status: http.StatusAccepted, validateRequest: true},
+		{name: "run unknown job", method: http.MethodPost, target: "/api/v1/admin/jobs/unknown/run", apiKey: "admin", status: http.StatusNotFound, validateRequest: true},
+		{name: "invalid judgment", method: http.MethodPost, target: "/api/v1/judgments", body: `{"documentId": "GH-1", "candidateId": "GH-1", "duplicate": true}`, apiKey: "key", status: http.StatusBadRequest, validateRequest: true},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, testCase.target, strings.NewReader(testCase.body))
+			if testCase.body != "" {
+				request.Header.Set("Content-Type", "application/json")
+			}
+			if testCase.apiKey != "" {
+				request.Header.Set("X-Api-Key", testCase.apiKey)
+			}
+
+			route, pathParams, err := router.FindRoute(request)
+			if err != nil {
+				t.Fatalf("route not documented: %s", err)
+			}
+
+			requestInput := &openapi3filter.RequestValidationInput{
+				Request:    request,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+			}
+
+			if testCase.validateRequest {
+				if err := openapi3filter.ValidateRequest(context.Background(), requestInput); err != nil {
+					t.Fatalf("request does not match the specification: %s", err)
+				}
+
+				// Validation consumes the body.
+				request.Body = io.NopCloser(strings.NewReader(testCase.body))
+			}
+
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, request)
+
+			if recorder.Code != testCase.status {
+				t.Fatalf("expected status %d, got %d: %s", testCase.status, recorder.Code, recorder.Body.String())
+			}
+
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: requestInput,
+				Status:                 recorder.Code,
+				Header:                 recorder.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(recorder.Body.Bytes())),
+			}
+
+			if err := openapi3filter.ValidateResponse(context.Background(), responseInput); err != nil {
+				t.Fatalf("response does not match the specification: %s", err)
+			}
+		})
+	}
+}
+
+func TestOpenAPISpecificationIsValid(t *testing.T) {
+	loader := openapi3.NewLoader()
+
+	doc, err := loader.LoadFromData(api.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := doc.Validate(loader.Context); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openAPIRouter(t *testing.T) routers.Router {
+	t.Helper()
+
+	doc, err := openapi3.NewLoader().LoadFromData(api.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return router
+}
+
+func contractTestMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+
+	fakeOpensearch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/" || r.URL.Path == "/issues":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/_plugins/_ml/models/model":
+			_, _ = w.Write([]byte(`{"name": "all-MiniLM-L6-v2", "model_state": "DEPLOYED"}`))
+		case strings.HasSuffix(r.URL.Path, "/_search"):
+			_, _ = w.Write([]byte(fakeSearchResponse))
+		case strings.HasSuffix(r.URL.Path, "/_doc/GH-1"):
+			_, _ = w.Write([]byte(`{"_index": "issues", "_id": "GH-1", "found": true, "_source": {"title": "Github Issues are fancy"}}`))
+		case strings.Contains(r.URL.Path, "/_doc/"):
+			if r.Method == http.MethodGet {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"_index": "issues", "_id": "GH-404", "found": false}`))
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"result": "created"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(fakeOpensearch.Close)
+
+	opensearchClient, err := opensearch.NewClient(opensearch.Config{Addresses: []string{fakeOpensearch.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{IndexName: "issues", ModelId: "model", OpensearchUrl: fakeOpensearch.URL, ApiKeys: []string{"key"}, AdminApiKeys: []string{"admin"}, OpensearchClient: opensearchClient}
+	logger := zap.NewNop().Sugar()
+	noMiddleware := func(handler http.Handler) http.Handler { return handler }
+
+	mux := http.NewServeMux()
+	registerApiHandlers(mux, cfg, logger, noMiddleware)
+	registerJudgmentHandlers(mux, cfg, logger, noMiddleware)
+	registerHealthHandlers(mux, health.NewChecker(cfg, logger), logger)
+	registerJobHandlers(mux, scheduler.New(nil, 0, 0, logger, scheduler.Job{Name: "github-sync", Interval: time.Hour, Run: func(context.Context) error { return nil }}), cfg, logger, noMiddleware)
+
+	return mux
+}
+
+func TestClientAgainstApi(t *testing.T) {
+	server := httptest.NewServer(contractTestMux(t))
+	defer server.Close()
+
+	apiClient := client.New(server.URL, "key", nil)
+
+	result, err := apiClient.Search(context.Background(), client.SearchRequest{Title: "fancy", Explain: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Hits.Hits) != 1 || result.Hits.Hits[0].Breakdown["title"] != 1.8 {
+		t.Errorf("unexpected search result: %+v", result.Hits)
+	}
+
+	if _, err := apiClient.Similar(context.Background(), "GH-1", client.SearchRequest{OnlyPublic: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var apiError *client.Error
+	if _, err := apiClient.Similar(context.Background(), "GH-404", client.SearchRequest{}); !errors.As(err, &apiError) || apiError.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	if err := apiClient.RecordJudgment(context.Background(), judgment.Judgment{DocumentId: "GH-1", CandidateId: "GH-2", Duplicate: true}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSearchBySourceExcludesOtherSources makes sure source=github does not match the analyzed source field, which
+// would include "github-discussions" as well.
+func TestSearchBySourceExcludesOtherSources(t *testing.T) {
+	var query string
+
+	fakeOpensearch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query = string(body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fakeSearchResponse))
+	}))
+	defer fakeOpensearch.Close()
+
+	opensearchClient, err := opensearch.NewClient(opensearch.Config{Addresses: []string{fakeOpensearch.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{IndexName: "issues", ModelId: "model", ApiKeys: []string{"key"}, OpensearchClient: opensearchClient}
+
+	mux := http.NewServeMux()
+	registerApiHandlers(mux, cfg, zap.NewNop().Sugar(), func(handler http.Handler) http.Handler { return handler })
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/search?title=fancy&source=github", nil)
+	request.Header.Set("X-Api-Key", "key")
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	if !strings.Contains(query, `{ "term": { "source.keyword": "github" }}`) || strings.Contains(query, `"match": { "source"`) {
+		t.Errorf("expected an exact filter on the source, got %s", query)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+
+	"go.uber.org/zap"
+)
+
+func TestRequireApiKey(t *testing.T) {
+	cfg := config.Config{ApiKeys: []string{"first", "second"}}
+	handler := requireApiKey(cfg, zap.NewNop().Sugar(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for name, testCase := range map[string]struct {
+		header string
+		value  string
+		status int
+	}{
+		"missing key":   {status: http.StatusUnauthorized},
+		"invalid key":   {header: "X-Api-Key", value: "third", status: http.StatusUnauthorized},
+		"api key":       {header: "X-Api-Key", value: "second", status: http.StatusNoContent},
+		"bearer token":  {header: "Authorization", value: "Bearer first", status: http.StatusNoContent},
+		"invalid token": {header: "Authorization", value: "Basic first", status: http.StatusUnauthorized},
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/search", nil)
+		if testCase.header != "" {
+			request.Header.Set(testCase.header, testCase.value)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != testCase.status {
+			t.Errorf("%s: expected status %d, got %d", name, testCase.status, recorder.Code)
+		}
+	}
+}
+
+func TestRequireApiKeyWithoutConfiguredKeys(t *testing.T) {
+	handler := requireApiKey(config.Config{}, zap.NewNop().Sugar(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/search", nil)
+	request.Header.Set("X-Api-Key", "")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, recorder.Code)
+	}
+}
+
+func TestSearchRequestFromQuery(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/search?title=Paging&description=broken&sources=github,jira&onlyPublic=true&minScore=2&size=5&from=10&status=open&labels=bug&explain=true", nil)
+
+	actual, err := searchRequestFromQuery(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := SearchRequest{
+		Title:       "Paging",
+		Description: "broken",
+		Sources:     []string{"github", "jira"},
+		OnlyPublic:  true,
+		MinScore:    2,
+		Size:        5,
+		From:        10,
+		Status:      "open",
+		Labels:      []string{"bug"},
+		Explain:     true,
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected search request %+v, got %+v", expected, actual)
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/search?size=many", nil)
+	if _, err := searchRequestFromQuery(request); err == nil {
+		t.Error("expected an error for an invalid size")
+	}
+}
+
+func TestRequireAdminApiKey(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		cfg    config.Config
+		key    string
+		status int
+	}{
+		"admin key":                  {cfg: config.Config{ApiKeys: []string{"search"}, AdminApiKeys: []string{"admin"}}, key: "admin", status: http.StatusNoContent},
+		"search key":                 {cfg: config.Config{ApiKeys: []string{"search"}, AdminApiKeys: []string{"admin"}}, key: "search", status: http.StatusUnauthorized},
+		"without admin keys":         {cfg: config.Config{ApiKeys: []string{"search"}}, key: "search", status: http.StatusUnauthorized},
+		"without any configured key": {cfg: config.Config{}, key: "", status: http.StatusUnauthorized},
+	} {
+		handler := requireAdminApiKey(testCase.cfg, zap.NewNop().Sugar(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/admin/jobs/github-sync/run", nil)
+		request.Header.Set("X-Api-Key", testCase.key)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != testCase.status {
+			t.Errorf("%s: expected status %d, got %d", name, testCase.status, recorder.Code)
+		}
+	}
+}
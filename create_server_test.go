@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/scheduler"
+
+	"go.uber.org/zap"
+)
+
+func TestCreateServerUsesOwnMux(t *testing.T) {
+	cfg := config.Config{ServerAddr: "127.0.0.1:8080", ServerReadTimeout: time.Second, ServerWriteTimeout: 2 * time.Second}
+
+	// Registering on http.DefaultServeMux would panic for the second server.
+	first := createServer(cfg, scheduler.New(nil, 0, 0, zap.NewNop().Sugar()), context.Background())
+	second := createServer(cfg, scheduler.New(nil, 0, 0, zap.NewNop().Sugar()), context.Background())
+
+	for _, server := range []*http.Server{first, second} {
+		if server.Addr != cfg.ServerAddr || server.ReadTimeout != cfg.ServerReadTimeout || server.WriteTimeout != cfg.ServerWriteTimeout {
+			t.Errorf("server does not use the configured address and timeouts: %+v", server)
+		}
+
+		recorder := httptest.NewRecorder()
+		server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		if recorder.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+		}
+	}
+}
+
+func TestCreateServerRoutesConnectorWebhooks(t *testing.T) {
+	server := createServer(config.Config{}, scheduler.New(nil, 0, 0, zap.NewNop().Sugar()), context.Background())
+
+	recorder := httptest.NewRecorder()
+	server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/webhook/github", nil))
+
+	// The request reaches the GitHub handler, which rejects the missing signature.
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestCreateServerAcknowledgesSlackEventsBeforeHandling(t *testing.T) {
+	cfg := config.Config{SlackSigningSecret: "secret"}
+	server := createServer(cfg, scheduler.New(nil, 0, 0, zap.NewNop().Sugar()), context.Background())
+
+	body := `{"type": "event_callback", "event": {"type": "member_joined_channel", "user": "U1", "channel": "C1"}}`
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	signature := hmac.New(sha256.New, []byte(cfg.SlackSigningSecret))
+	signature.Write([]byte("v0:" + timestamp + ":" + body))
+
+	request := httptest.NewRequest(http.MethodPost, "/slack/event", strings.NewReader(body))
+	request.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	request.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(signature.Sum(nil)))
+
+	recorder := httptest.NewRecorder()
+	server.Handler.ServeHTTP(recorder, request)
+	slackWork.Wait()
+
+	// Slack retries every event which is not acknowledged with 200, even when the bot ignores it.
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+}
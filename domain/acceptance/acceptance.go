@@ -0,0 +1,191 @@
+// Package acceptance measures how well the search finds the known duplicates of the duplicates/ dataset.
+package acceptance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/samber/lo"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// Case is a document of the dataset with the documents it duplicates. GitHub issues are stored as
+// <dir>/<issue number>.json, documents of other sources as <dir>/<document id>.json, see CaseFile.
+type Case struct {
+	Id          string   `json:"-"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Matches     []string `json:"matches"`
+}
+
+// SearchFunc returns the ids of the hits for a case, best hit first.
+type SearchFunc func(c Case, ctx context.Context) ([]string, error)
+
+type CaseResult struct {
+	Id             string   `json:"id"`
+	Expected       []string `json:"expected"`
+	Found          []string `json:"found"`
+	Missing        []string `json:"missing"`
+	Unexpected     []string `json:"unexpected"`
+	Precision      float64  `json:"precision"`
+	Recall         float64  `json:"recall"`
+	ReciprocalRank float64  `json:"reciprocalRank"`
+	NDCG           float64  `json:"ndcg"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// Report holds the metrics averaged over all cases, each at the cutoff K.
+type Report struct {
+	Profile   string       `json:"profile,omitempty"`
+	K         int          `json:"k"`
+	Cases     int          `json:"cases"`
+	Errors    int          `json:"errors"`
+	Precision float64      `json:"precision"`
+	Recall    float64      `json:"recall"`
+	MRR       float64      `json:"mrr"`
+	NDCG      float64      `json:"ndcg"`
+	Results   []CaseResult `json:"results"`
+}
+
+// Thresholds are the minimum values of the metrics, a zero threshold is not checked.
+type Thresholds struct {
+	Precision float64 `json:"precision,omitempty"`
+	Recall    float64 `json:"recall,omitempty"`
+	MRR       float64 `json:"mrr,omitempty"`
+	NDCG      float64 `json:"ndcg,omitempty"`
+}
+
+func LoadDataset(dir string) ([]Case, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading directory: %w", err)
+	}
+
+	var cases []Case
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var c Case
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", file.Name(), err)
+		}
+
+		c.Id = caseId(file.Name())
+		cases = append(cases, c)
+	}
+
+	return cases, nil
+}
+
+// DefaultSearch runs the search like the bot does for a new GitHub issue, but returns k hits.
+func DefaultSearch(k int, config config.Config) SearchFunc {
+	return func(c Case, ctx context.Context) ([]string, error) {
+		result, err := search.Search(c.Title, c.Description, search.SearchFilter{ExcludedDocumentId: c.Id, Size: k}, config, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return hitIds(result), nil
+	}
+}
+
+func hitIds(result *search.SearchResponse) []string {
+	ids := make([]string, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		ids = append(ids, hit.ID)
+	}
+
+	return ids
+}
+
+// Evaluate searches every case, a failed search counts as a case without hits.
+func Evaluate(cases []Case, k int, searchFunc SearchFunc, ctx context.Context) Report {
+	report := Report{K: k, Cases: len(cases), Results: make([]CaseResult, len(cases))}
+
+	search.Parallel(len(cases), func(i int) {
+		found, err := searchFunc(cases[i], ctx)
+		report.Results[i] = evaluateCase(cases[i], found, k, err)
+	})
+
+	sort.Slice(report.Results, func(i, j int) bool {
+		return report.Results[i].Id < report.Results[j].Id
+	})
+
+	for _, result := range report.Results {
+		if result.Error != "" {
+			report.Errors++
+		}
+
+		report.Precision += result.Precision
+		report.Recall += result.Recall
+		report.MRR += result.ReciprocalRank
+		report.NDCG += result.NDCG
+	}
+
+	if len(cases) > 0 {
+		report.Precision /= float64(len(cases))
+		report.Recall /= float64(len(cases))
+		report.MRR /= float64(len(cases))
+		report.NDCG /= float64(len(cases))
+	}
+
+	return report
+}
+
+func evaluateCase(c Case, found []string, k int, err error) CaseResult {
+	found = limit(found, k)
+	missing, unexpected := lo.Difference(c.Matches, found)
+
+	result := CaseResult{
+		Id:             c.Id,
+		Expected:       c.Matches,
+		Found:          found,
+		Missing:        missing,
+		Unexpected:     unexpected,
+		Precision:      PrecisionAtK(found, c.Matches, k),
+		Recall:         RecallAtK(found, c.Matches, k),
+		ReciprocalRank: ReciprocalRank(found, c.Matches),
+		NDCG:           NDCGAtK(found, c.Matches, k),
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Failures describes every metric below its threshold, the report passes if there are none.
+func (r Report) Failures(thresholds Thresholds) []string {
+	var failures []string
+
+	check := func(name string, value float64, threshold float64) {
+		if threshold > 0 && value < threshold {
+			failures = append(failures, fmt.Sprintf("%s %.3f is below %.3f", name, value, threshold))
+		}
+	}
+
+	check(fmt.Sprintf("precision@%d", r.K), r.Precision, thresholds.Precision)
+	check(fmt.Sprintf("recall@%d", r.K), r.Recall, thresholds.Recall)
+	check("MRR", r.MRR, thresholds.MRR)
+	check(fmt.Sprintf("nDCG@%d", r.K), r.NDCG, thresholds.NDCG)
+
+	if r.Errors > 0 {
+		failures = append(failures, fmt.Sprintf("%d searches failed", r.Errors))
+	}
+
+	return failures
+}
@@ -0,0 +1,334 @@
+package acceptance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+func assertMetric(t *testing.T, name string, expected float64, actual float64) {
+	t.Helper()
+
+	if math.Abs(expected-actual) > 0.0001 {
+		t.Errorf("expected %s to be %.4f, got %.4f", name, expected, actual)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	ranked := []string{"A", "B", "C", "D"}
+	relevant := []string{"B", "D", "E"}
+
+	assertMetric(t, "precision@2", 0.5, PrecisionAtK(ranked, relevant, 2))
+	assertMetric(t, "precision@4", 0.5, PrecisionAtK(ranked, relevant, 4))
+	assertMetric(t, "recall@2", 1.0/3, RecallAtK(ranked, relevant, 2))
+	assertMetric(t, "recall@4", 2.0/3, RecallAtK(ranked, relevant, 4))
+	assertMetric(t, "reciprocal rank", 0.5, ReciprocalRank(ranked, relevant))
+
+	// DCG = 1/log2(3) + 1/log2(5), ideal DCG = 1 + 1/log2(3) + 1/log2(4).
+	assertMetric(t, "nDCG@4", (1/math.Log2(3)+1/math.Log2(5))/(1+1/math.Log2(3)+0.5), NDCGAtK(ranked, relevant, 4))
+	assertMetric(t, "perfect nDCG", 1, NDCGAtK([]string{"B", "D"}, []string{"D", "B"}, 5))
+
+	assertMetric(t, "reciprocal rank without relevant hits", 0, ReciprocalRank(ranked, []string{"X"}))
+	assertMetric(t, "recall without expected matches", 1, RecallAtK(ranked, nil, 5))
+}
+
+func TestEvaluate(t *testing.T) {
+	cases := []Case{
+		{Id: "GH-2", Matches: []string{"NEXT-1"}},
+		{Id: "GH-1", Matches: []string{"NEXT-2", "NEXT-3"}},
+		{Id: "GH-3", Matches: []string{"NEXT-4"}},
+	}
+
+	searchFunc := func(c Case, ctx context.Context) ([]string, error) {
+		switch c.Id {
+		case "GH-1":
+			return []string{"NEXT-2", "NEXT-9", "NEXT-3"}, nil
+		case "GH-2":
+			return []string{"NEXT-9", "NEXT-1"}, nil
+		}
+
+		return nil, errors.New("timeout")
+	}
+
+	report := Evaluate(cases, 2, searchFunc, context.Background())
+
+	if report.Cases != 3 || report.Errors != 1 || report.Results[0].Id != "GH-1" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if missing := report.Results[0].Missing; len(missing) != 1 || missing[0] != "NEXT-3" {
+		t.Errorf("expected NEXT-3 to be missing beyond k, got %v", missing)
+	}
+
+	assertMetric(t, "precision", (0.5+0.5+0)/3, report.Precision)
+	assertMetric(t, "recall", (0.5+1+0)/3, report.Recall)
+	assertMetric(t, "MRR", (1+0.5+0)/3, report.MRR)
+
+	failures := report.Failures(Thresholds{Precision: 0.3, Recall: 0.9})
+	if len(failures) != 2 || !strings.HasPrefix(failures[0], "recall@2") || failures[1] != "1 searches failed" {
+		t.Errorf("unexpected failures: %v", failures)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	report := Report{K: 5, Cases: 2, Precision: 0.2, Results: []CaseResult{
+		{Id: "GH-1", Missing: []string{"NEXT-1"}},
+		{Id: "GH-2"},
+	}}
+
+	var buffer bytes.Buffer
+	if err := WriteJUnit(&buffer, []Report{report}, Thresholds{Precision: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buffer.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid XML: %s\n%s", err, buffer.String())
+	}
+
+	suite := suites.Suites[0]
+	if suite.Tests != 6 || suite.Failures != 2 || suite.TestCases[0].Failure == nil || suite.TestCases[4].Failure == nil {
+		t.Errorf("unexpected test suite: %+v", suite)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "title-heavy.yaml")
+	if err := os.WriteFile(path, []byte("index: issues-v2\nminScore: 1.5\ntitleWeight: 2.5\nlexicalWeight: 0.1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := LoadProfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.Name != "title-heavy" || profile.Index != "issues-v2" || profile.MinScore != 1.5 || profile.TitleWeight != 2.5 || profile.LexicalWeight != 0.1 {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+
+	if err := os.WriteFile(path, []byte("titleWieght: 2.5\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadProfile(path); err == nil {
+		t.Error("expected unknown fields to be rejected")
+	}
+}
+
+func TestProfileMatchesBot(t *testing.T) {
+	cfg := config.Config{IndexName: "issues", ModelId: "model"}
+
+	for name, testCase := range map[string]struct {
+		profile  Profile
+		expected bool
+	}{
+		"empty profile":     {profile: Profile{Name: "default"}, expected: true},
+		"explicit defaults": {profile: Profile{Index: "issues", ModelId: "model", Ranking: search.Ranking{TitleWeight: search.DefaultTitleWeight}}, expected: true},
+		"min score":         {profile: Profile{MinScore: 1.5}, expected: true},
+		"other index":       {profile: Profile{Index: "issues-v2"}},
+		"other model":       {profile: Profile{ModelId: "other"}},
+		"other ranking":     {profile: Profile{Ranking: search.Ranking{LexicalWeight: 0.1}}},
+	} {
+		if actual := testCase.profile.MatchesBot(cfg); actual != testCase.expected {
+			t.Errorf("%s: expected %t, got %t", name, testCase.expected, actual)
+		}
+	}
+}
+
+func TestChanges(t *testing.T) {
+	baseline := Report{Profile: "a", Results: []CaseResult{
+		{Id: "GH-1", Found: []string{"A", "B"}, NDCG: 1},
+		{Id: "GH-2", Found: []string{"C", "D"}, NDCG: 0.5},
+		{Id: "GH-3", Found: []string{"E"}, NDCG: 0},
+	}}
+	other := Report{Profile: "b", Results: []CaseResult{
+		{Id: "GH-1", Found: []string{"B", "A"}, NDCG: 1},
+		{Id: "GH-2", Found: []string{"D"}, NDCG: 0},
+		{Id: "GH-3", Found: []string{"E"}, NDCG: 0},
+	}}
+
+	changes := Changes(baseline, other, 10)
+	if len(changes) != 2 || changes[0].Id != "GH-2" || changes[0].Delta() != -0.5 || changes[1].Id != "GH-1" {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteComparison(&buffer, []Report{baseline, other}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buffer.String(), "GH-2 nDCG 0.500 -> 0.000 (-0.500): C, D -> D") || strings.Contains(buffer.String(), "GH-1 nDCG") {
+		t.Errorf("unexpected comparison:\n%s", buffer.String())
+	}
+}
+
+func TestComputeCurve(t *testing.T) {
+	hits := []ScoredHit{
+		{Id: "A", Score: 2, Relevant: false},
+		{Id: "B", Score: 3, Relevant: true},
+		{Id: "C", Score: 2.5, Relevant: true},
+		{Id: "D", Score: 1, Relevant: true},
+		{Id: "E", Score: 2.5, Relevant: false},
+	}
+
+	curve := ComputeCurve("all", hits, 1)
+	if len(curve.Points) != 4 || curve.Relevant != 3 || curve.Points[1].Hits != 3 {
+		t.Fatalf("unexpected curve: %+v", curve)
+	}
+
+	assertMetric(t, "precision at 2.5", 2.0/3, curve.Points[1].Precision)
+	assertMetric(t, "recall at 2.5", 2.0/3, curve.Points[1].Recall)
+	assertMetric(t, "optimal threshold", 1, curve.Optimal.Threshold)
+	assertMetric(t, "optimal F1", 0.75, curve.Optimal.FScore)
+
+	// Favouring precision moves the threshold above all false matches.
+	assertMetric(t, "optimal threshold with beta 0.5", 3, ComputeCurve("all", hits, 0.5).Optimal.Threshold)
+
+	sampled := sampleCurve(curve, 1)
+	if len(sampled) != 3 || sampled[0].Threshold != 3 || sampled[1].Threshold != 2 || sampled[2].Threshold != 1 {
+		t.Errorf("unexpected sampled curve: %+v", sampled)
+	}
+}
+
+func TestTune(t *testing.T) {
+	report := Tune([]ScoredHit{
+		{Id: "A", Source: "Jira", Public: true, Score: 3, Relevant: true},
+		{Id: "B", Source: "GitHub", Public: true, Score: 2, Relevant: false},
+		{Id: "C", Source: "Jira", Public: false, Score: 2.5, Relevant: true},
+	})
+
+	if len(report.Sources) != 2 || report.Sources[0].Name != "GitHub" || report.Sources[1].Hits != 2 {
+		t.Errorf("unexpected sources: %+v", report.Sources)
+	}
+
+	comment, err := report.UseCase("github-comment")
+	if err != nil || comment.Hits != 2 || comment.Optimal.Threshold != 3 {
+		t.Errorf("unexpected github comment curve: %+v, %v", comment, err)
+	}
+
+	if _, err := report.UseCase("email"); err == nil {
+		t.Error("expected unknown use case to fail")
+	}
+}
+
+func TestJiraDuplicates(t *testing.T) {
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+" "+r.URL.Query().Get("nextPageToken"))
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Query().Get("nextPageToken") == "" {
+			fmt.Fprint(w, `{"nextPageToken": "page-2", "isLast": false, "issues": [{"key": "NEXT-1", "fields": {"summary": "Cart",
+				"description": {"type": "doc", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Cart is"}, {"type": "hardBreak"}, {"type": "text", "text": "empty"}]}]},
+				"issuelinks": [{"type": {"outward": "duplicates"}, "outwardIssue": {"key": "NEXT-2"}}]}}]}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"isLast": true, "issues": [{"key": "NEXT-3", "fields": {"summary": "No links", "issuelinks": []}}]}`)
+	}))
+	defer server.Close()
+
+	duplicates, err := JiraDuplicates("project = NEXT", config.Config{JiraUrl: server.URL, JiraEmail: "bot@example.com", JiraToken: "token"}, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(requests, ",") != "/rest/api/3/search/jql ,/rest/api/3/search/jql page-2" {
+		t.Errorf("expected both pages of the JQL search, got %v", requests)
+	}
+
+	if len(duplicates) != 1 || duplicates[0].Id != "NEXT-1" || duplicates[0].Description != "Cart is\nempty" || strings.Join(duplicates[0].Of, ",") != "NEXT-2" {
+		t.Errorf("unexpected duplicates: %+v", duplicates)
+	}
+}
+
+func TestSelectMatches(t *testing.T) {
+	selected, err := SelectMatches("2, 1 NEXT-12 2 GH-1234\n", []string{"GH-1", "SO-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(selected, ",") != "SO-2,GH-1,NEXT-12,GH-1234" {
+		t.Errorf("unexpected selection: %v", selected)
+	}
+
+	for _, input := range []string{"3", "0", "duplicate"} {
+		if _, err := SelectMatches(input, []string{"GH-1", "SO-2"}); err == nil {
+			t.Errorf("expected \"%s\" to be rejected", input)
+		}
+	}
+}
+
+func TestImportDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "1.json"), []byte(`{"title": "Labelled", "description": "by hand", "matches": ["GH-2"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	created, extended, err := ImportDuplicates(dir, []Duplicate{
+		{Id: "GH-1", Title: "Harvested", Of: []string{"GH-2", "GH-3"}},
+		{Id: "GH-4", Title: "Harvested", Of: []string{"GH-2"}},
+		{Id: "NEXT-5", Title: "Jira", Of: []string{"NEXT-6"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created != 2 || extended != 1 {
+		t.Errorf("expected 2 created and 1 extended case, got %d and %d", created, extended)
+	}
+
+	cases, err := LoadDataset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byId := map[string]Case{}
+	for _, c := range cases {
+		byId[c.Id] = c
+	}
+
+	if c := byId["GH-1"]; c.Title != "Labelled" || strings.Join(c.Matches, ",") != "GH-2,GH-3" {
+		t.Errorf("unexpected extended case: %+v", c)
+	}
+
+	if c := byId["NEXT-5"]; c.Title != "Jira" || strings.Join(c.Matches, ",") != "NEXT-6" {
+		t.Errorf("unexpected Jira case: %+v", byId)
+	}
+}
+
+func TestParseDuplicates(t *testing.T) {
+	of := parseDuplicateOf(10, "Duplicate of #12\n\nduplicate of https://github.com/shopware/platform/issues/13, not a duplicate of #11\nThis isn't a duplicate of #14\nDuplicate of #10")
+	if strings.Join(of, ",") != "GH-12,GH-13" {
+		t.Errorf("unexpected GitHub duplicates: %v", of)
+	}
+
+	var issue jiraIssue
+	if err := json.Unmarshal([]byte(`{"key": "NEXT-1", "fields": {"issuelinks": [
+		{"type": {"outward": "duplicates"}, "outwardIssue": {"key": "NEXT-2"}},
+		{"type": {"outward": "duplicates"}, "inwardIssue": {"key": "NEXT-3"}},
+		{"type": {"outward": "blocks"}, "outwardIssue": {"key": "NEXT-4"}}
+	]}}`), &issue); err != nil {
+		t.Fatal(err)
+	}
+
+	if of := issue.duplicateOf(); strings.Join(of, ",") != "NEXT-2" {
+		t.Errorf("unexpected Jira duplicates: %v", of)
+	}
+}
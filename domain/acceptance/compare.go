@@ -0,0 +1,152 @@
+package acceptance
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Change is a case whose hits differ between the baseline and another profile.
+type Change struct {
+	Id            string   `json:"id"`
+	BaselineNDCG  float64  `json:"baselineNdcg"`
+	NDCG          float64  `json:"ndcg"`
+	BaselineFound []string `json:"baselineFound"`
+	Found         []string `json:"found"`
+}
+
+func (c Change) Delta() float64 {
+	return c.NDCG - c.BaselineNDCG
+}
+
+// Changes returns up to limit cases whose hits changed the most, the largest nDCG difference first.
+// Cases with the same nDCG but reordered or replaced hits follow.
+func Changes(baseline Report, other Report, limit int) []Change {
+	baselineResults := make(map[string]CaseResult, len(baseline.Results))
+	for _, result := range baseline.Results {
+		baselineResults[result.Id] = result
+	}
+
+	var changes []Change
+
+	for _, result := range other.Results {
+		baselineResult, ok := baselineResults[result.Id]
+		if !ok || sameIds(baselineResult.Found, result.Found) {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Id:            result.Id,
+			BaselineNDCG:  baselineResult.NDCG,
+			NDCG:          result.NDCG,
+			BaselineFound: baselineResult.Found,
+			Found:         result.Found,
+		})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		left, right := math.Abs(changes[i].Delta()), math.Abs(changes[j].Delta())
+		if left != right {
+			return left > right
+		}
+
+		return positionChanges(changes[i]) > positionChanges(changes[j])
+	})
+
+	if limit >= 0 && len(changes) > limit {
+		changes = changes[:limit]
+	}
+
+	return changes
+}
+
+// WriteComparison prints the metrics of all profiles with their difference to the first one,
+// followed by the cases which changed the most in every other profile.
+func WriteComparison(w io.Writer, reports []Report, limit int) error {
+	if len(reports) == 0 {
+		return nil
+	}
+
+	baseline := reports[0]
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprint(table, "metric\t")
+	for _, report := range reports {
+		fmt.Fprintf(table, "%s\t", report.Profile)
+	}
+	fmt.Fprintln(table)
+
+	row := func(name string, value func(report Report) float64) {
+		fmt.Fprintf(table, "%s\t%.3f\t", name, value(baseline))
+
+		for _, report := range reports[1:] {
+			fmt.Fprintf(table, "%.3f (%+.3f)\t", value(report), value(report)-value(baseline))
+		}
+
+		fmt.Fprintln(table)
+	}
+
+	row(fmt.Sprintf("precision@%d", baseline.K), func(report Report) float64 { return report.Precision })
+	row(fmt.Sprintf("recall@%d", baseline.K), func(report Report) float64 { return report.Recall })
+	row("MRR", func(report Report) float64 { return report.MRR })
+	row(fmt.Sprintf("nDCG@%d", baseline.K), func(report Report) float64 { return report.NDCG })
+	row("errors", func(report Report) float64 { return float64(report.Errors) })
+
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, report := range reports[1:] {
+		changes := Changes(baseline, report, limit)
+
+		fmt.Fprintf(w, "\nMost changed issues of %s compared to %s:\n", report.Profile, baseline.Profile)
+
+		if len(changes) == 0 {
+			fmt.Fprintln(w, "  none")
+			continue
+		}
+
+		for _, change := range changes {
+			fmt.Fprintf(w, "  %s nDCG %.3f -> %.3f (%+.3f): %s -> %s\n",
+				change.Id, change.BaselineNDCG, change.NDCG, change.Delta(), listOrNone(change.BaselineFound), listOrNone(change.Found))
+		}
+	}
+
+	return nil
+}
+
+func sameIds(left []string, right []string) bool {
+	if len(left) != len(right) {
+		return false
+	}
+
+	for i := range left {
+		if left[i] != right[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func positionChanges(change Change) int {
+	changed := 0
+	for i := 0; i < len(change.Found) || i < len(change.BaselineFound); i++ {
+		if i >= len(change.Found) || i >= len(change.BaselineFound) || change.Found[i] != change.BaselineFound[i] {
+			changed++
+		}
+	}
+
+	return changed
+}
+
+func listOrNone(ids []string) string {
+	if len(ids) == 0 {
+		return "none"
+	}
+
+	return strings.Join(ids, ", ")
+}
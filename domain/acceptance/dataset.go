@@ -0,0 +1,145 @@
+package acceptance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/samber/lo"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+var documentIdPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*-\d+$`)
+
+// NormalizeId accepts a document id or a plain GitHub issue number.
+func NormalizeId(id string) (string, error) {
+	id = strings.TrimSpace(id)
+
+	if _, err := strconv.Atoi(id); err == nil {
+		return "GH-" + id, nil
+	}
+
+	if !documentIdPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid document id \"%s\", expected e.g. GH-1234, NEXT-12345 or SO-123", id)
+	}
+
+	return id, nil
+}
+
+// CaseFile is the path of the case, GitHub issues keep their number as file name.
+func CaseFile(dir string, id string) string {
+	return filepath.Join(dir, strings.TrimPrefix(id, "GH-")+".json")
+}
+
+func caseId(fileName string) string {
+	name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+
+	if _, err := strconv.Atoi(name); err == nil {
+		return "GH-" + name
+	}
+
+	return name
+}
+
+// LoadCase returns false if the dataset has no case for the id yet.
+func LoadCase(dir string, id string) (Case, bool, error) {
+	data, err := os.ReadFile(CaseFile(dir, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Case{Id: id}, false, nil
+	}
+
+	if err != nil {
+		return Case{}, false, err
+	}
+
+	c := Case{Id: id}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Case{}, false, fmt.Errorf("failed to decode %s: %w", CaseFile(dir, id), err)
+	}
+
+	return c, true, nil
+}
+
+func SaveCase(dir string, c Case) error {
+	if len(c.Matches) == 0 {
+		return fmt.Errorf("case %s has no matches", c.Id)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(CaseFile(dir, c.Id), append(data, '\n'), 0644)
+}
+
+// AddMatches adds the ids which are not matches of the case yet and returns how many were added.
+func (c *Case) AddMatches(ids ...string) int {
+	added := 0
+
+	for _, id := range ids {
+		if id == c.Id || lo.Contains(c.Matches, id) {
+			continue
+		}
+
+		c.Matches = append(c.Matches, id)
+		added++
+	}
+
+	return added
+}
+
+// Candidates returns the indexed document and its top hits for labelling, independent of the minimum score.
+func Candidates(id string, size int, config config.Config, ctx context.Context) (*search.Document, []search.IssueResult, error) {
+	document, err := search.GetDocument(id, config, ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch %s: %w", id, err)
+	}
+
+	filter := search.SearchFilter{ExcludedDocumentId: id, Size: size, MinScore: collectMinScore}
+
+	result, err := search.Search(document.Title, document.Description, filter, config, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return document, result.Hits.Hits, nil
+}
+
+// SelectMatches parses a labelling answer like "1, 3 NEXT-123", numbers refer to the candidates starting at 1,
+// so GitHub issues which are not candidates need their GH- prefix.
+func SelectMatches(input string, candidates []string) ([]string, error) {
+	var selected []string
+
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		if number, err := strconv.Atoi(field); err == nil {
+			if number < 1 || number > len(candidates) {
+				return nil, fmt.Errorf("there is no candidate %d", number)
+			}
+
+			selected = append(selected, candidates[number-1])
+			continue
+		}
+
+		id, err := NormalizeId(field)
+		if err != nil {
+			return nil, err
+		}
+
+		selected = append(selected, id)
+	}
+
+	return lo.Uniq(selected), nil
+}
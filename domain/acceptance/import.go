@@ -0,0 +1,331 @@
+package acceptance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/samber/lo"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+)
+
+const (
+	githubOwner      = "shopware"
+	githubRepository = "platform"
+)
+
+// duplicateOfPattern matches the "Duplicate of #123" comment GitHub uses to close an issue as duplicate,
+// also with a link to an issue of the same repository. It has to start the line, so "not a duplicate of #123"
+// does not match.
+var duplicateOfPattern = regexp.MustCompile(`(?im)^\s*duplicate of (?:#|https://github\.com/` + githubOwner + `/` + githubRepository + `/issues/)(\d+)`)
+
+var jiraClient = metrics.HTTPClient("jira")
+
+// Duplicate is a document a source marked as duplicate of other documents.
+type Duplicate struct {
+	Id          string
+	Title       string
+	Description string
+	Of          []string
+}
+
+// GithubDuplicates harvests the issues closed since the given time with a "Duplicate of #123" comment.
+func GithubDuplicates(since time.Time, config config.Config, ctx context.Context) ([]Duplicate, error) {
+	logger := logging.FromContext(ctx)
+
+	options := &github.IssueListByRepoOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+		State:       "closed",
+		Sort:        "updated",
+		Direction:   "desc",
+		Since:       since,
+	}
+
+	var duplicates []Duplicate
+
+	for {
+		issues, response, err := config.GithubClient.Issues.ListByRepo(ctx, githubOwner, githubRepository, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list closed issues: %w", err)
+		}
+
+		for _, issue := range issues {
+			if issue.IsPullRequest() || !closedAsDuplicate(issue) {
+				continue
+			}
+
+			of, err := githubDuplicateOf(issue, config, ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(of) == 0 {
+				logger.Debugf("Issue #%d is closed as duplicate, but has no \"Duplicate of\" comment", issue.GetNumber())
+				continue
+			}
+
+			duplicates = append(duplicates, Duplicate{
+				Id:          fmt.Sprintf("GH-%d", issue.GetNumber()),
+				Title:       issue.GetTitle(),
+				Description: issue.GetBody(),
+				Of:          of,
+			})
+		}
+
+		if response.NextPage == 0 {
+			return duplicates, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+// closedAsDuplicate skips completed issues, so only their comments are fetched which can be duplicates.
+func closedAsDuplicate(issue *github.Issue) bool {
+	if issue.GetStateReason() == "duplicate" || issue.GetStateReason() == "not_planned" {
+		return true
+	}
+
+	for _, label := range issue.Labels {
+		if strings.Contains(strings.ToLower(label.GetName()), "duplicate") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func githubDuplicateOf(issue *github.Issue, config config.Config, ctx context.Context) ([]string, error) {
+	of := parseDuplicateOf(issue.GetNumber(), issue.GetBody())
+
+	options := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		comments, response, err := config.GithubClient.Issues.ListComments(ctx, githubOwner, githubRepository, issue.GetNumber(), options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments of issue #%d: %w", issue.GetNumber(), err)
+		}
+
+		for _, comment := range comments {
+			of = append(of, parseDuplicateOf(issue.GetNumber(), comment.GetBody())...)
+		}
+
+		if response.NextPage == 0 {
+			return lo.Uniq(of), nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+func parseDuplicateOf(number int, text string) []string {
+	var of []string
+
+	for _, match := range duplicateOfPattern.FindAllStringSubmatch(text, -1) {
+		duplicate, _ := strconv.Atoi(match[1])
+		if duplicate != number {
+			of = append(of, fmt.Sprintf("GH-%d", duplicate))
+		}
+	}
+
+	return of
+}
+
+type jiraSearchResponse struct {
+	Issues        []jiraIssue `json:"issues"`
+	NextPageToken string      `json:"nextPageToken"`
+	IsLast        bool        `json:"isLast"`
+}
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string  `json:"summary"`
+		Description adfNode `json:"description"`
+		IssueLinks  []struct {
+			Type struct {
+				Outward string `json:"outward"`
+			} `json:"type"`
+			OutwardIssue *struct {
+				Key string `json:"key"`
+			} `json:"outwardIssue"`
+		} `json:"issuelinks"`
+	} `json:"fields"`
+}
+
+// duplicateOf returns the issues this issue links with "duplicates", the inward "is duplicated by"
+// side is harvested from the other issue.
+func (i jiraIssue) duplicateOf() []string {
+	var of []string
+
+	for _, link := range i.Fields.IssueLinks {
+		if link.OutwardIssue != nil && strings.EqualFold(link.Type.Outward, "duplicates") {
+			of = append(of, link.OutwardIssue.Key)
+		}
+	}
+
+	return of
+}
+
+// adfNode is a node of the Atlassian Document Format, the version 3 API returns descriptions in it.
+type adfNode struct {
+	Type    string    `json:"type"`
+	Text    string    `json:"text"`
+	Content []adfNode `json:"content"`
+}
+
+// adfBlocks end with a line break in the plain text.
+var adfBlocks = []string{"paragraph", "heading", "blockquote", "codeBlock", "listItem", "rule"}
+
+// plainText returns the text of the node and its children, formatting is dropped.
+func (n adfNode) plainText() string {
+	var text strings.Builder
+
+	n.writeText(&text)
+
+	return strings.TrimSpace(text.String())
+}
+
+func (n adfNode) writeText(text *strings.Builder) {
+	if n.Type == "hardBreak" {
+		text.WriteString("\n")
+	}
+
+	text.WriteString(n.Text)
+
+	for _, child := range n.Content {
+		child.writeText(text)
+	}
+
+	if lo.Contains(adfBlocks, n.Type) {
+		text.WriteString("\n")
+	}
+}
+
+// CheckJiraCredentials fails without the credentials JiraDuplicates needs, so harvests can fail before they start.
+func CheckJiraCredentials(config config.Config) error {
+	if config.JiraEmail == "" || config.JiraToken == "" {
+		return fmt.Errorf("JIRA_EMAIL and JIRA_TOKEN are required to import from Jira, use --source github to skip it")
+	}
+
+	return nil
+}
+
+// JiraDuplicates harvests the issues matching jql with a "duplicates" link.
+func JiraDuplicates(jql string, config config.Config, ctx context.Context) ([]Duplicate, error) {
+	if err := CheckJiraCredentials(config); err != nil {
+		return nil, err
+	}
+
+	var duplicates []Duplicate
+
+	for pageToken := ""; ; {
+		page, err := searchJira(jql, pageToken, config, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, issue := range page.Issues {
+			if of := issue.duplicateOf(); len(of) > 0 {
+				duplicates = append(duplicates, Duplicate{
+					Id:          issue.Key,
+					Title:       issue.Fields.Summary,
+					Description: issue.Fields.Description.plainText(),
+					Of:          of,
+				})
+			}
+		}
+
+		if page.IsLast || page.NextPageToken == "" {
+			return duplicates, nil
+		}
+
+		pageToken = page.NextPageToken
+	}
+}
+
+// searchJira fetches a page of the enhanced JQL search, the first page has an empty token.
+func searchJira(jql string, pageToken string, config config.Config, ctx context.Context) (*jiraSearchResponse, error) {
+	query := url.Values{}
+	query.Set("jql", jql)
+	query.Set("fields", "summary,description,issuelinks")
+	query.Set("maxResults", "100")
+	if pageToken != "" {
+		query.Set("nextPageToken", pageToken)
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.JiraTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(config.JiraUrl, "/")+"/rest/api/3/search/jql?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetBasicAuth(config.JiraEmail, config.JiraToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := jiraClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search Jira: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to search Jira, status %d: %s", resp.StatusCode, body)
+	}
+
+	var page jiraSearchResponse
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode Jira search: %w", err)
+	}
+
+	return &page, nil
+}
+
+// ImportDuplicates adds the duplicates to the dataset, existing cases keep their title, description and matches
+// and only get the new matches. It returns the number of created and extended cases.
+func ImportDuplicates(dir string, duplicates []Duplicate) (int, int, error) {
+	created, extended := 0, 0
+
+	for _, duplicate := range duplicates {
+		c, exists, err := LoadCase(dir, duplicate.Id)
+		if err != nil {
+			return created, extended, err
+		}
+
+		if !exists {
+			c.Title = duplicate.Title
+			c.Description = duplicate.Description
+		}
+
+		if c.AddMatches(duplicate.Of...) == 0 {
+			continue
+		}
+
+		if err := SaveCase(dir, c); err != nil {
+			return created, extended, err
+		}
+
+		if exists {
+			extended++
+		} else {
+			created++
+		}
+	}
+
+	return created, extended, nil
+}
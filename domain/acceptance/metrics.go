@@ -0,0 +1,94 @@
+package acceptance
+
+import "math"
+
+// The metrics use binary relevance: a hit is relevant if it is one of the expected matches of the case.
+
+// PrecisionAtK is the share of the first k hits which are relevant.
+func PrecisionAtK(ranked []string, relevant []string, k int) float64 {
+	if k <= 0 {
+		return 0
+	}
+
+	return float64(relevantHits(ranked, relevant, k)) / float64(k)
+}
+
+// RecallAtK is the share of the relevant documents which are within the first k hits.
+// A case without relevant documents has nothing to miss, so its recall is 1.
+func RecallAtK(ranked []string, relevant []string, k int) float64 {
+	if len(relevant) == 0 {
+		return 1
+	}
+
+	return float64(relevantHits(ranked, relevant, k)) / float64(len(relevant))
+}
+
+// ReciprocalRank is 1 divided by the rank of the first relevant hit, or 0 if there is none.
+func ReciprocalRank(ranked []string, relevant []string) float64 {
+	set := toSet(relevant)
+
+	for i, id := range ranked {
+		if set[id] {
+			return 1 / float64(i+1)
+		}
+	}
+
+	return 0
+}
+
+// NDCGAtK compares the discounted gain of the first k hits with the gain of a perfect ranking.
+func NDCGAtK(ranked []string, relevant []string, k int) float64 {
+	if len(relevant) == 0 {
+		return 1
+	}
+
+	set := toSet(relevant)
+
+	dcg := 0.0
+	for i, id := range limit(ranked, k) {
+		if set[id] {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+
+	ideal := 0.0
+	for i := 0; i < len(set) && i < k; i++ {
+		ideal += 1 / math.Log2(float64(i+2))
+	}
+
+	if ideal == 0 {
+		return 0
+	}
+
+	return dcg / ideal
+}
+
+func relevantHits(ranked []string, relevant []string, k int) int {
+	set := toSet(relevant)
+
+	hits := 0
+	for _, id := range limit(ranked, k) {
+		if set[id] {
+			hits++
+		}
+	}
+
+	return hits
+}
+
+func limit(ranked []string, k int) []string {
+	if k < len(ranked) {
+		return ranked[:k]
+	}
+
+	return ranked
+}
+
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	return set
+}
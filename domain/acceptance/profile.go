@@ -0,0 +1,83 @@
+package acceptance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a ranking configuration to evaluate, e.g.
+//
+//	name: title-heavy
+//	index: issues-v2
+//	minScore: 1.5
+//	titleWeight: 2.5
+//	descriptionWeight: 1
+//	lexicalWeight: 0.1
+//
+// Index and model default to INDEX_NAME and the configured model, so indices built with another
+// model can be compared with the current one.
+type Profile struct {
+	Name           string  `yaml:"name"`
+	Index          string  `yaml:"index"`
+	ModelId        string  `yaml:"modelId"`
+	MinScore       float64 `yaml:"minScore"`
+	search.Ranking `yaml:",inline"`
+}
+
+// LoadProfile reads a YAML profile, its name defaults to the file name.
+func LoadProfile(path string) (Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	var profile Profile
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&profile); err != nil {
+		return Profile{}, fmt.Errorf("failed to decode profile %s: %w", path, err)
+	}
+
+	if profile.Name == "" {
+		profile.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	return profile, nil
+}
+
+// MatchesBot reports whether the profile searches the index of the bot with its model and ranking, the scores of
+// other profiles are not comparable to the scores of the bot.
+func (p Profile) MatchesBot(config config.Config) bool {
+	return (p.Index == "" || p.Index == config.IndexName) && (p.ModelId == "" || p.ModelId == config.ModelId) && p.Ranking.IsDefault()
+}
+
+// ProfileSearch is DefaultSearch with the index, model and ranking of the profile.
+func ProfileSearch(profile Profile, k int, config config.Config) SearchFunc {
+	if profile.Index != "" {
+		config.IndexName = profile.Index
+	}
+
+	if profile.ModelId != "" {
+		config.ModelId = profile.ModelId
+	}
+
+	return func(c Case, ctx context.Context) ([]string, error) {
+		filter := search.SearchFilter{ExcludedDocumentId: c.Id, Size: k, MinScore: profile.MinScore, Ranking: profile.Ranking}
+
+		result, err := search.Search(c.Title, c.Description, filter, config, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return hitIds(result), nil
+	}
+}
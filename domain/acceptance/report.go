@@ -0,0 +1,168 @@
+package acceptance
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteSummary prints the metrics as a table, followed by the cases which missed expected matches.
+func WriteSummary(w io.Writer, report Report, thresholds Thresholds) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(table, "metric\tvalue\tthreshold\t\n")
+
+	row := func(name string, value float64, threshold float64) {
+		limit := "-"
+		if threshold > 0 {
+			limit = fmt.Sprintf("%.3f", threshold)
+		}
+
+		fmt.Fprintf(table, "%s\t%.3f\t%s\t\n", name, value, limit)
+	}
+
+	row(fmt.Sprintf("precision@%d", report.K), report.Precision, thresholds.Precision)
+	row(fmt.Sprintf("recall@%d", report.K), report.Recall, thresholds.Recall)
+	row("MRR", report.MRR, thresholds.MRR)
+	row(fmt.Sprintf("nDCG@%d", report.K), report.NDCG, thresholds.NDCG)
+
+	fmt.Fprintf(table, "cases\t%d\t\t\n", report.Cases)
+	fmt.Fprintf(table, "errors\t%d\t\t\n", report.Errors)
+
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, result := range report.Results {
+		if result.Error != "" {
+			fmt.Fprintf(w, "%s: search failed: %s\n", result.Id, result.Error)
+			continue
+		}
+
+		if len(result.Missing) > 0 {
+			fmt.Fprintf(w, "%s: missing %s, found %s\n", result.Id, strings.Join(result.Missing, ", "), strings.Join(result.Found, ", "))
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes a report, or a list of reports when profiles were compared.
+func WriteJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes one test suite per report. Each suite has one test case per metric, which fails
+// below its threshold, and one per dataset case, which fails if expected matches are missing.
+// CI systems can then track both over time.
+func WriteJUnit(w io.Writer, reports []Report, thresholds Thresholds) error {
+	suites := junitTestSuites{}
+	for _, report := range reports {
+		suites.Suites = append(suites.Suites, junitSuite(report, thresholds))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+func junitSuite(report Report, thresholds Thresholds) junitTestSuite {
+	suite := junitTestSuite{
+		Name: "acceptance",
+		Properties: []junitProperty{
+			{Name: "k", Value: fmt.Sprint(report.K)},
+			{Name: "precision", Value: fmt.Sprintf("%.4f", report.Precision)},
+			{Name: "recall", Value: fmt.Sprintf("%.4f", report.Recall)},
+			{Name: "mrr", Value: fmt.Sprintf("%.4f", report.MRR)},
+			{Name: "ndcg", Value: fmt.Sprintf("%.4f", report.NDCG)},
+		},
+	}
+
+	if report.Profile != "" {
+		suite.Name += "." + report.Profile
+	}
+
+	metric := func(name string, value float64, threshold float64) {
+		testCase := junitTestCase{ClassName: suite.Name + ".metrics", Name: name}
+		if threshold > 0 && value < threshold {
+			testCase.Failure = &junitMessage{Message: fmt.Sprintf("%s %.3f is below %.3f", name, value, threshold)}
+			suite.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	metric(fmt.Sprintf("precision@%d", report.K), report.Precision, thresholds.Precision)
+	metric(fmt.Sprintf("recall@%d", report.K), report.Recall, thresholds.Recall)
+	metric("MRR", report.MRR, thresholds.MRR)
+	metric(fmt.Sprintf("nDCG@%d", report.K), report.NDCG, thresholds.NDCG)
+
+	for _, result := range report.Results {
+		testCase := junitTestCase{ClassName: suite.Name + ".cases", Name: result.Id}
+
+		if result.Error != "" {
+			testCase.Error = &junitMessage{Message: "search failed", Text: result.Error}
+			suite.Errors++
+		} else if len(result.Missing) > 0 {
+			testCase.Failure = &junitMessage{
+				Message: "missing " + strings.Join(result.Missing, ", "),
+				Text:    "found " + strings.Join(result.Found, ", "),
+			}
+			suite.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	suite.Tests = len(suite.TestCases)
+
+	return suite
+}
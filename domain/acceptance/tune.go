@@ -0,0 +1,282 @@
+package acceptance
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// collectMinScore is low enough to return every hit, a zero min score would use search.DefaultMinScore.
+const collectMinScore = 0.01
+
+// ScoredHit is a hit of a dataset search with its raw score, Relevant tells whether it is a labelled duplicate.
+type ScoredHit struct {
+	CaseId   string  `json:"caseId"`
+	Id       string  `json:"id"`
+	Source   string  `json:"source"`
+	Public   bool    `json:"public"`
+	Score    float64 `json:"score"`
+	Relevant bool    `json:"relevant"`
+}
+
+// UseCase weighs precision against recall with the beta of the F-score, a beta below 1 favours precision.
+type UseCase struct {
+	Name       string
+	Beta       float64
+	OnlyPublic bool
+}
+
+// UseCases are the places the bot shows search results. A wrong public comment on a new GitHub issue
+// costs more than a missing one, in Slack somebody asked and a near miss is still helpful.
+var UseCases = []UseCase{
+	{Name: "github-comment", Beta: 0.5, OnlyPublic: true},
+	{Name: "slack-answer", Beta: 1},
+}
+
+type CurvePoint struct {
+	Threshold float64 `json:"threshold"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	FScore    float64 `json:"fScore"`
+	Hits      int     `json:"hits"`
+}
+
+// Curve is the precision/recall curve of a group of hits. Recall is relative to the labelled duplicates
+// the search returned at all, duplicates it never returns cannot be fixed by a threshold.
+type Curve struct {
+	Name     string       `json:"name"`
+	Beta     float64      `json:"beta"`
+	Hits     int          `json:"hits"`
+	Relevant int          `json:"relevant"`
+	Optimal  CurvePoint   `json:"optimal"`
+	Points   []CurvePoint `json:"points"`
+}
+
+type TuneReport struct {
+	Profile  string  `json:"profile,omitempty"`
+	Cases    int     `json:"cases"`
+	Errors   int     `json:"errors"`
+	Overall  Curve   `json:"overall"`
+	UseCases []Curve `json:"useCases"`
+	Sources  []Curve `json:"sources"`
+}
+
+// UseCase returns the curve of the named use case.
+func (r TuneReport) UseCase(name string) (Curve, error) {
+	for _, curve := range r.UseCases {
+		if curve.Name == name {
+			return curve, nil
+		}
+	}
+
+	return Curve{}, fmt.Errorf("unknown use case \"%s\"", name)
+}
+
+// ScoreSearch returns up to size hits of the profile without a minimum score.
+func ScoreSearch(profile Profile, size int, config config.Config) func(c Case, ctx context.Context) ([]search.IssueResult, error) {
+	if profile.Index != "" {
+		config.IndexName = profile.Index
+	}
+
+	if profile.ModelId != "" {
+		config.ModelId = profile.ModelId
+	}
+
+	return func(c Case, ctx context.Context) ([]search.IssueResult, error) {
+		filter := search.SearchFilter{ExcludedDocumentId: c.Id, Size: size, MinScore: collectMinScore, Ranking: profile.Ranking}
+
+		result, err := search.Search(c.Title, c.Description, filter, config, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return result.Hits.Hits, nil
+	}
+}
+
+// CollectScores searches every case and labels the hits, failed searches are counted and skipped.
+func CollectScores(cases []Case, searchFunc func(c Case, ctx context.Context) ([]search.IssueResult, error), ctx context.Context) ([]ScoredHit, int) {
+	var hits []ScoredHit
+	var errors int
+
+	var lock sync.Mutex
+
+	search.Parallel(len(cases), func(i int) {
+		c := cases[i]
+
+		results, err := searchFunc(c, ctx)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		if err != nil {
+			errors++
+			return
+		}
+
+		relevant := toSet(c.Matches)
+		for _, result := range results {
+			hits = append(hits, ScoredHit{
+				CaseId:   c.Id,
+				Id:       result.ID,
+				Source:   result.Source.Source,
+				Public:   result.Source.Public,
+				Score:    result.Score,
+				Relevant: relevant[result.ID],
+			})
+		}
+	})
+
+	return hits, errors
+}
+
+func Tune(hits []ScoredHit) TuneReport {
+	report := TuneReport{Overall: ComputeCurve("all", hits, 1)}
+
+	for _, useCase := range UseCases {
+		var useCaseHits []ScoredHit
+		for _, hit := range hits {
+			if !useCase.OnlyPublic || hit.Public {
+				useCaseHits = append(useCaseHits, hit)
+			}
+		}
+
+		report.UseCases = append(report.UseCases, ComputeCurve(useCase.Name, useCaseHits, useCase.Beta))
+	}
+
+	bySource := map[string][]ScoredHit{}
+	for _, hit := range hits {
+		bySource[hit.Source] = append(bySource[hit.Source], hit)
+	}
+
+	for source, sourceHits := range bySource {
+		report.Sources = append(report.Sources, ComputeCurve(source, sourceHits, 1))
+	}
+
+	sort.Slice(report.Sources, func(i, j int) bool {
+		return report.Sources[i].Name < report.Sources[j].Name
+	})
+
+	return report
+}
+
+// ComputeCurve has a point for every distinct score, a hit is predicted as duplicate if its score is at
+// least the threshold. The optimal point has the highest F-score, on ties the higher threshold wins.
+func ComputeCurve(name string, hits []ScoredHit, beta float64) Curve {
+	sorted := append([]ScoredHit(nil), hits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Score > sorted[j].Score
+	})
+
+	curve := Curve{Name: name, Beta: beta, Hits: len(sorted)}
+	for _, hit := range sorted {
+		if hit.Relevant {
+			curve.Relevant++
+		}
+	}
+
+	truePositives := 0
+
+	for i, hit := range sorted {
+		if hit.Relevant {
+			truePositives++
+		}
+
+		// Only the last hit with a score is a threshold, all hits with the same score are predicted together.
+		if i+1 < len(sorted) && sorted[i+1].Score == hit.Score {
+			continue
+		}
+
+		point := CurvePoint{
+			Threshold: hit.Score,
+			Precision: float64(truePositives) / float64(i+1),
+			Hits:      i + 1,
+		}
+
+		if curve.Relevant > 0 {
+			point.Recall = float64(truePositives) / float64(curve.Relevant)
+		}
+
+		point.FScore = fScore(point.Precision, point.Recall, beta)
+
+		if point.FScore > curve.Optimal.FScore {
+			curve.Optimal = point
+		}
+
+		curve.Points = append(curve.Points, point)
+	}
+
+	return curve
+}
+
+func fScore(precision float64, recall float64, beta float64) float64 {
+	if precision == 0 && recall == 0 {
+		return 0
+	}
+
+	return (1 + beta*beta) * precision * recall / (beta*beta*precision + recall)
+}
+
+// WriteTuneSummary prints the optimal thresholds and the overall curve in steps of step.
+func WriteTuneSummary(w io.Writer, report TuneReport, step float64) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(table, "group\tbeta\thits\tduplicates\tthreshold\tprecision\trecall\tF\t\n")
+
+	row := func(name string, curve Curve) {
+		fmt.Fprintf(table, "%s\t%.1f\t%d\t%d\t%.3f\t%.3f\t%.3f\t%.3f\t\n",
+			name, curve.Beta, curve.Hits, curve.Relevant, curve.Optimal.Threshold, curve.Optimal.Precision, curve.Optimal.Recall, curve.Optimal.FScore)
+	}
+
+	row("all", report.Overall)
+	for _, curve := range report.UseCases {
+		row(curve.Name, curve)
+	}
+	for _, curve := range report.Sources {
+		row("source "+curve.Name, curve)
+	}
+
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nPrecision/recall curve of all hits:\n")
+
+	fmt.Fprintf(table, "threshold\tprecision\trecall\tF\thits\t\n")
+	for _, point := range sampleCurve(report.Overall, step) {
+		fmt.Fprintf(table, "%.3f\t%.3f\t%.3f\t%.3f\t%d\t\n", point.Threshold, point.Precision, point.Recall, point.FScore, point.Hits)
+	}
+
+	return table.Flush()
+}
+
+// sampleCurve keeps the lowest point above every multiple of step, like the curve it is sorted by descending threshold.
+func sampleCurve(curve Curve, step float64) []CurvePoint {
+	if step <= 0 {
+		return curve.Points
+	}
+
+	var sampled []CurvePoint
+	bucket := math.Inf(1)
+
+	for _, point := range curve.Points {
+		current := math.Floor(point.Threshold / step)
+
+		if current == bucket {
+			sampled[len(sampled)-1] = point
+			continue
+		}
+
+		sampled = append(sampled, point)
+		bucket = current
+	}
+
+	return sampled
+}
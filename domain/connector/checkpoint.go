@@ -0,0 +1,93 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// Checkpoint is the high-water mark of the last successful sync of a source.
+type Checkpoint struct {
+	Source string `json:"source"`
+	// Since is the time from which the next sync fetches changes, as unix timestamp.
+	Since int64 `json:"since"`
+	// Date is the time the checkpoint was saved, as unix timestamp.
+	Date    int64 `json:"date"`
+	Indexed int   `json:"indexed"`
+}
+
+// GetCheckpoint returns nil if the source was never synced.
+func GetCheckpoint(source string, config config.Config, ctx context.Context) (*Checkpoint, error) {
+	req := opensearchapi.GetRequest{
+		Index:      checkpointIndex(config),
+		DocumentID: source,
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.SearchTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sync checkpoint: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("failed to fetch sync checkpoint: %s", body)
+	}
+
+	var stored struct {
+		Source Checkpoint `json:"_source"`
+	}
+
+	if err := json.Unmarshal(body, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode sync checkpoint: %w", err)
+	}
+
+	return &stored.Source, nil
+}
+
+func SaveCheckpoint(checkpoint Checkpoint, config config.Config, ctx context.Context) error {
+	jsonString, _ := json.Marshal(checkpoint)
+
+	req := opensearchapi.IndexRequest{
+		Index:      checkpointIndex(config),
+		DocumentID: checkpoint.Source,
+		Body:       bytes.NewReader(jsonString),
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.IndexTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		return fmt.Errorf("failed to save sync checkpoint: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		body, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("failed to save sync checkpoint: %s", body)
+	}
+
+	return nil
+}
+
+func checkpointIndex(config config.Config) string {
+	return config.IndexName + "-sync-checkpoints"
+}
@@ -0,0 +1,70 @@
+// Package connector drives downloading, indexing and syncing of all sources through one interface,
+// so adding a source only requires fetching its items and mapping them to a search.Document.
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// Connector is a source of documents like GitHub or Stack Overflow.
+type Connector interface {
+	// Name identifies the source on the CLI, in webhook URLs and as download folder, e.g. "github".
+	Name() string
+	// Fetch calls yield for every item which changed since the given time, the zero time fetches all items.
+	// Items are passed as returned by the API of the source, so they can be stored and mapped later.
+	Fetch(ctx context.Context, config config.Config, since time.Time, yield func(item json.RawMessage) error) error
+	// Map converts an item to its id in the index, e.g. "GH-1", and the indexed document.
+	Map(item json.RawMessage) (string, search.Document, error)
+}
+
+// WebhookConnector is implemented by sources which push changes to /webhook/<name>.
+type WebhookConnector interface {
+	Connector
+	// WebhookEvent returns the event type of a webhook request for the metrics.
+	WebhookEvent(r *http.Request) string
+	Webhook(config config.Config) http.Handler
+}
+
+var registry = map[string]Connector{}
+
+// Register makes connectors available to the download, index and sync commands and the webhook routing.
+func Register(connectors ...Connector) {
+	for _, connector := range connectors {
+		if _, exists := registry[connector.Name()]; exists {
+			panic(fmt.Sprintf("connector \"%s\" is already registered", connector.Name()))
+		}
+
+		registry[connector.Name()] = connector
+	}
+}
+
+func Get(name string) (Connector, error) {
+	connector, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown source \"%s\"", name)
+	}
+
+	return connector, nil
+}
+
+// All returns the registered connectors sorted by name.
+func All() []Connector {
+	connectors := make([]Connector, 0, len(registry))
+	for _, connector := range registry {
+		connectors = append(connectors, connector)
+	}
+
+	sort.Slice(connectors, func(i, j int) bool {
+		return connectors[i].Name() < connectors[j].Name()
+	})
+
+	return connectors
+}
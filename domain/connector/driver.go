@@ -0,0 +1,199 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+)
+
+const (
+	// indexConcurrency limits the parallel index requests, so OpenSearch can keep up creating the embeddings.
+	indexConcurrency = 14
+	// checkpointOverlap is subtracted from the start of a sync when saving the checkpoint, so items
+	// are not missed if the clock of the source is behind. Items in the overlap are indexed twice.
+	checkpointOverlap = 5 * time.Minute
+)
+
+// Result counts the items of a run, failed items are logged and skipped.
+type Result struct {
+	Indexed int
+	Failed  int
+}
+
+// Download stores every item of the source as <dir>/<id>.json, so it can be indexed again without fetching it.
+func Download(connector Connector, dir string, config config.Config, ctx context.Context) (int, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	downloaded := 0
+
+	err := connector.Fetch(ctx, config, time.Time{}, func(item json.RawMessage) error {
+		id, _, err := connector.Map(item)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, fileName(id)), item, 0600); err != nil {
+			return err
+		}
+
+		downloaded++
+
+		return nil
+	})
+
+	return downloaded, err
+}
+
+// IndexFolder indexes all items which were downloaded to dir.
+func IndexFolder(connector Connector, dir string, config config.Config, ctx context.Context) (Result, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read downloaded items, run download %s first: %w", connector.Name(), err)
+	}
+
+	indexer := newIndexer(connector, config, ctx)
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		item, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return indexer.wait(), err
+		}
+
+		indexer.add(item)
+	}
+
+	return indexer.wait(), ctx.Err()
+}
+
+// Sync fetches the items which changed since the given time and indexes them right away.
+func Sync(connector Connector, since time.Time, config config.Config, ctx context.Context) (Result, error) {
+	indexer := newIndexer(connector, config, ctx)
+
+	err := connector.Fetch(ctx, config, since, func(item json.RawMessage) error {
+		indexer.add(item)
+
+		return nil
+	})
+
+	return indexer.wait(), err
+}
+
+// SyncFromCheckpoint syncs everything which changed since the last successful sync, or all items
+// if the source was never synced. The checkpoint is only moved forward if every item was indexed,
+// failed items are fetched again by the next run.
+func SyncFromCheckpoint(connector Connector, config config.Config, ctx context.Context) (Result, error) {
+	logger := logging.FromContext(ctx)
+
+	checkpoint, err := GetCheckpoint(connector.Name(), config, ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var since time.Time
+	if checkpoint != nil {
+		since = time.Unix(checkpoint.Since, 0)
+		logger.Infof("Syncing %s changes since %s", connector.Name(), since.Format(time.RFC3339))
+	} else {
+		logger.Infof("No checkpoint for %s, syncing all items", connector.Name())
+	}
+
+	started := time.Now()
+
+	result, err := Sync(connector, since, config, ctx)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Failed > 0 {
+		return result, fmt.Errorf("failed to index %d %s items, keeping the checkpoint", result.Failed, connector.Name())
+	}
+
+	err = SaveCheckpoint(Checkpoint{
+		Source:  connector.Name(),
+		Since:   started.Add(-checkpointOverlap).Unix(),
+		Date:    time.Now().Unix(),
+		Indexed: result.Indexed,
+	}, config, ctx)
+
+	return result, err
+}
+
+// Index maps and indexes a single item.
+func Index(connector Connector, item json.RawMessage, config config.Config, ctx context.Context) error {
+	id, document, err := connector.Map(item)
+	if err != nil {
+		return fmt.Errorf("failed to map %s item: %w", connector.Name(), err)
+	}
+
+	if err := search.IndexDocument(id, document, config, ctx); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debugf("Indexed %s document: %s", connector.Name(), id)
+
+	return nil
+}
+
+type indexer struct {
+	connector Connector
+	config    config.Config
+	ctx       context.Context
+	guard     chan struct{}
+	wg        sync.WaitGroup
+	lock      sync.Mutex
+	result    Result
+}
+
+func newIndexer(connector Connector, config config.Config, ctx context.Context) *indexer {
+	return &indexer{connector: connector, config: config, ctx: ctx, guard: make(chan struct{}, indexConcurrency)}
+}
+
+func (i *indexer) add(item json.RawMessage) {
+	i.guard <- struct{}{}
+	i.wg.Add(1)
+
+	go func() {
+		defer func() {
+			<-i.guard
+			i.wg.Done()
+		}()
+
+		err := Index(i.connector, item, i.config, i.ctx)
+
+		i.lock.Lock()
+		defer i.lock.Unlock()
+
+		if err != nil {
+			logging.FromContext(i.ctx).Error(err)
+			i.result.Failed++
+			return
+		}
+
+		i.result.Indexed++
+	}()
+}
+
+func (i *indexer) wait() Result {
+	i.wg.Wait()
+
+	return i.result
+}
+
+func fileName(id string) string {
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(id) + ".json"
+}
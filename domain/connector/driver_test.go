@@ -0,0 +1,246 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+type fakeItem struct {
+	Id      int       `json:"id"`
+	Title   string    `json:"title"`
+	Updated time.Time `json:"updated"`
+}
+
+type fakeConnector struct {
+	items []fakeItem
+}
+
+func (fakeConnector) Name() string {
+	return "fake"
+}
+
+func (c fakeConnector) Fetch(ctx context.Context, config config.Config, since time.Time, yield func(item json.RawMessage) error) error {
+	for _, item := range c.items {
+		if item.Updated.Before(since) {
+			continue
+		}
+
+		data, _ := json.Marshal(item)
+		if err := yield(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (fakeConnector) Map(item json.RawMessage) (string, search.Document, error) {
+	var fake fakeItem
+	if err := json.Unmarshal(item, &fake); err != nil {
+		return "", search.Document{}, err
+	}
+
+	if fake.Title == "" {
+		return "", search.Document{}, errors.New("missing title")
+	}
+
+	return fmt.Sprintf("FAKE-%d", fake.Id), search.Document{Title: fake.Title, Source: "fake"}, nil
+}
+
+func fakeIndex(t *testing.T) (config.Config, func() []string) {
+	t.Helper()
+
+	var lock sync.Mutex
+	var indexed []string
+	var checkpoint []byte
+
+	fakeOpensearch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasPrefix(r.URL.Path, "/issues-sync-checkpoints/_doc/") {
+			if r.Method == http.MethodGet {
+				if checkpoint == nil {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"found": false}`))
+					return
+				}
+
+				_, _ = w.Write([]byte(`{"found": true, "_source": ` + string(checkpoint) + `}`))
+				return
+			}
+
+			checkpoint, _ = io.ReadAll(r.Body)
+		} else if index := strings.Index(r.URL.Path, "/_doc/"); index >= 0 {
+			indexed = append(indexed, r.URL.Path[index+len("/_doc/"):])
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"result": "created"}`))
+	}))
+	t.Cleanup(fakeOpensearch.Close)
+
+	client, err := opensearch.NewClient(opensearch.Config{Addresses: []string{fakeOpensearch.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return config.Config{IndexName: "issues", OpensearchClient: client}, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return indexed
+	}
+}
+
+func TestDownloadAndIndexFolder(t *testing.T) {
+	cfg, indexed := fakeIndex(t)
+	dir := filepath.Join(t.TempDir(), "fake")
+	source := fakeConnector{items: []fakeItem{{Id: 1, Title: "first"}, {Id: 2, Title: "second"}, {Id: 3}}}
+
+	if _, err := Download(source, dir, cfg, context.Background()); err == nil {
+		t.Fatal("expected the item without title to fail the download")
+	}
+
+	source.items = source.items[:2]
+
+	downloaded, err := Download(source, dir, cfg, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if downloaded != 2 {
+		t.Errorf("expected 2 downloaded items, got %d", downloaded)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "FAKE-1.json")); err != nil {
+		t.Errorf("expected the item to be stored by its id: %s", err)
+	}
+
+	// Broken files are counted as failed, they do not stop the other items from being indexed.
+	if err := os.WriteFile(filepath.Join(dir, "FAKE-3.json"), []byte(`{"id": 3}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := IndexFolder(source, dir, cfg, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != (Result{Indexed: 2, Failed: 1}) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if len(indexed()) != 2 {
+		t.Errorf("expected 2 indexed documents, got %v", indexed())
+	}
+}
+
+func TestSyncOnlyIndexesChangedItems(t *testing.T) {
+	cfg, indexed := fakeIndex(t)
+	now := time.Now()
+	source := fakeConnector{items: []fakeItem{
+		{Id: 1, Title: "old", Updated: now.Add(-48 * time.Hour)},
+		{Id: 2, Title: "new", Updated: now.Add(-time.Hour)},
+	}}
+
+	result, err := Sync(source, now.Add(-24*time.Hour), cfg, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != (Result{Indexed: 1}) || len(indexed()) != 1 || indexed()[0] != "FAKE-2" {
+		t.Errorf("expected only FAKE-2 to be indexed, got %+v %v", result, indexed())
+	}
+}
+
+func TestSyncFromCheckpoint(t *testing.T) {
+	cfg, indexed := fakeIndex(t)
+	source := fakeConnector{items: []fakeItem{{Id: 1, Title: "old", Updated: time.Now().Add(-48 * time.Hour)}, {Id: 2}}}
+
+	// Without a checkpoint everything is synced, the failed item keeps the checkpoint from being saved.
+	if _, err := SyncFromCheckpoint(source, cfg, context.Background()); err == nil {
+		t.Fatal("expected the failed item to fail the sync")
+	}
+
+	if checkpoint, err := GetCheckpoint("fake", cfg, context.Background()); err != nil || checkpoint != nil {
+		t.Fatalf("expected no checkpoint, got %+v %v", checkpoint, err)
+	}
+
+	source.items[1] = fakeItem{Id: 2, Title: "fixed", Updated: time.Now().Add(-48 * time.Hour)}
+
+	result, err := SyncFromCheckpoint(source, cfg, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Indexed != 2 {
+		t.Errorf("expected all items to be indexed, got %+v", result)
+	}
+
+	checkpoint, err := GetCheckpoint("fake", cfg, context.Background())
+	if err != nil || checkpoint == nil {
+		t.Fatalf("expected a checkpoint, got %+v %v", checkpoint, err)
+	}
+
+	if since := time.Unix(checkpoint.Since, 0); since.After(time.Now().Add(-checkpointOverlap)) || checkpoint.Indexed != 2 {
+		t.Errorf("unexpected checkpoint: %+v", checkpoint)
+	}
+
+	// The next sync only fetches the items which changed after the checkpoint.
+	source.items = append(source.items, fakeItem{Id: 3, Title: "new", Updated: time.Now()})
+	before := len(indexed())
+
+	if result, err := SyncFromCheckpoint(source, cfg, context.Background()); err != nil || result.Indexed != 1 {
+		t.Fatalf("expected only the new item to be synced, got %+v %v", result, err)
+	}
+
+	if last := indexed()[before:]; len(last) != 1 || last[0] != "FAKE-3" {
+		t.Errorf("expected FAKE-3 to be indexed, got %v", last)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	defer func() {
+		registry = map[string]Connector{}
+	}()
+
+	Register(fakeConnector{})
+
+	if _, err := Get("fake"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Get("unknown"); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+
+	if all := All(); len(all) != 1 || all[0].Name() != "fake" {
+		t.Errorf("unexpected connectors: %v", all)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a source twice to panic")
+		}
+	}()
+
+	Register(fakeConnector{})
+}
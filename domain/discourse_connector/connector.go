@@ -0,0 +1,207 @@
+package discourse_connector
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+)
+
+// Connector indexes the topics of the Discourse forum at DISCOURSE_URL.
+type Connector struct{}
+
+func (Connector) Name() string {
+	return "discourse"
+}
+
+// Fetch pages through the latest topics by their last activity, newest first, and stops at the first one older
+// than since. Pinned topics are listed first regardless of their activity.
+func (Connector) Fetch(ctx context.Context, config config.Config, since time.Time, yield func(item json.RawMessage) error) error {
+	for page := 0; ; page++ {
+		var list topicList
+		if err := getJson(fmt.Sprintf("/latest.json?order=activity&page=%d", page), &list, config, ctx); err != nil {
+			return fmt.Errorf("failed to list topics: %w", err)
+		}
+
+		for _, listed := range list.TopicList.Topics {
+			if !since.IsZero() && listed.BumpedAt.Before(since) {
+				if listed.Pinned {
+					continue
+				}
+
+				return nil
+			}
+
+			topic, err := getTopic(listed.Id, config, ctx)
+			if errors.Is(err, errNotFound) {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			data, err := json.Marshal(topic)
+			if err != nil {
+				return err
+			}
+
+			if err := yield(data); err != nil {
+				return err
+			}
+		}
+
+		if list.TopicList.MoreTopicsUrl == "" || len(list.TopicList.Topics) == 0 {
+			return nil
+		}
+	}
+}
+
+func (Connector) Map(item json.RawMessage) (string, search.Document, error) {
+	var topic Topic
+	if err := json.Unmarshal(item, &topic); err != nil {
+		return "", search.Document{}, err
+	}
+
+	return topicId(topic.Id), topicDocument(&topic), nil
+}
+
+// webhookEvents are the X-Discourse-Event values of the ping of a new webhook, of topic and post changes and of
+// solutions (un)accepted with the solved plugin. Other events are counted as metrics.OtherWebhookEvent.
+var webhookEvents = []string{
+	"ping", "topic_created", "topic_edited", "topic_destroyed", "topic_recovered",
+	"post_created", "post_edited", "post_destroyed", "post_recovered", "accepted_solution", "unaccepted_solution",
+}
+
+func (Connector) WebhookEvent(r *http.Request) string {
+	if event := r.Header.Get("X-Discourse-Event"); lo.Contains(webhookEvents, event) {
+		return event
+	}
+
+	return metrics.OtherWebhookEvent
+}
+
+// webhookPayload names the topic of topic, post and solved webhooks, only one of them is set.
+type webhookPayload struct {
+	Topic *struct {
+		Id int `json:"id"`
+	} `json:"topic"`
+	Post *struct {
+		TopicId int `json:"topic_id"`
+	} `json:"post"`
+	Solved *struct {
+		TopicId int `json:"topic_id"`
+	} `json:"solved"`
+}
+
+func (p webhookPayload) topicId() int {
+	switch {
+	case p.Topic != nil:
+		return p.Topic.Id
+	case p.Post != nil:
+		return p.Post.TopicId
+	case p.Solved != nil:
+		return p.Solved.TopicId
+	default:
+		return 0
+	}
+}
+
+func (Connector) Webhook(config config.Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if os.Getenv("CI") != "true" && !validSignature(payload, r.Header.Get("X-Discourse-Event-Signature"), config.DiscourseWebhookSecret) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		metrics.WebhookValidated(r.Context())
+
+		event := r.Header.Get("X-Discourse-Event")
+		if event == "ping" {
+			return
+		}
+
+		var body webhookPayload
+		if err := json.Unmarshal(payload, &body); err != nil || body.topicId() == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := HandleDiscourseEvent(event, body.topicId(), config, r.Context()); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	})
+}
+
+// validSignature checks the HMAC of the payload, Discourse sends it as "sha256=<hex>".
+func validSignature(payload []byte, signature string, secret string) bool {
+	if secret == "" {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return hmac.Equal([]byte(signature), []byte("sha256="+hex.EncodeToString(mac.Sum(nil))))
+}
+
+// HandleDiscourseEvent removes destroyed topics and reindexes the topic of all other events, a topic which is no
+// longer public is removed as well.
+func HandleDiscourseEvent(event string, id int, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	if event == "topic_destroyed" {
+		return deleteTopic(id, config, ctx)
+	}
+
+	topic, err := getTopic(id, config, ctx)
+	if errors.Is(err, errNotFound) {
+		return deleteTopic(id, config, ctx)
+	}
+
+	if err != nil {
+		logger.Errorf("Error while fetching Discourse topic: %s", err)
+
+		return err
+	}
+
+	if err := search.IndexDocument(topicId(id), topicDocument(topic), config, ctx); err != nil {
+		logger.Errorf("Error while indexing Discourse topic: %s", err)
+
+		return err
+	}
+
+	logger.Debugf("Indexed Discourse topic: %d", id)
+
+	return nil
+}
+
+func deleteTopic(id int, config config.Config, ctx context.Context) error {
+	if err := search.DeleteDocument(topicId(id), config, ctx); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debugf("Deleted Discourse topic: %d", id)
+
+	return nil
+}
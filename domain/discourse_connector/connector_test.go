@@ -0,0 +1,168 @@
+package discourse_connector
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// fakeForum serves a forum with a pinned topic, a solved topic whose answer is not part of the first posts and
+// an old topic, it records the requests of the forum and OpenSearch.
+func fakeForum(t *testing.T) (*[]string, config.Config) {
+	t.Helper()
+
+	var lock sync.Mutex
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		lock.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.RequestURI() {
+		case "/latest.json?order=activity&page=0":
+			fmt.Fprint(w, `{"topic_list": {"more_topics_url": "/latest?page=1", "topics": [
+				{"id": 1, "pinned": true, "bumped_at": "2023-01-01T00:00:00Z"},
+				{"id": 2, "bumped_at": "2023-05-03T00:00:00Z"}]}}`)
+		case "/latest.json?order=activity&page=1":
+			fmt.Fprint(w, `{"topic_list": {"topics": [{"id": 3, "bumped_at": "2023-04-01T00:00:00Z"}]}}`)
+		case "/t/2.json?include_raw=true":
+			fmt.Fprint(w, `{"id": 2, "title": "Cart is empty after login", "slug": "cart-is-empty", "like_count": 4,
+				"tags": ["checkout", {"name": "cart"}], "created_at": "2023-05-01T00:00:00Z", "accepted_answer": {"post_number": 30},
+				"post_stream": {"posts": [{"post_number": 1, "username": "jane", "raw": "My cart is empty"}]}}`)
+		case "/t/2/30.json?include_raw=true":
+			fmt.Fprint(w, `{"post_stream": {"posts": [{"post_number": 30, "username": "john", "raw": "Clear the cache"}]}}`)
+		case "/t/4.json?include_raw=true":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			fmt.Fprint(w, `{"result": "created"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := opensearch.NewClient(opensearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &requests, config.Config{IndexName: "issues", OpensearchClient: client, DiscourseUrl: server.URL + "/", DiscourseWebhookSecret: "secret"}
+}
+
+func TestFetchStopsAtSince(t *testing.T) {
+	_, cfg := fakeForum(t)
+
+	var topics []json.RawMessage
+	err := Connector{}.Fetch(context.Background(), cfg, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), func(item json.RawMessage) error {
+		topics = append(topics, item)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(topics) != 1 {
+		t.Fatalf("expected the pinned and the old topic to be skipped, got %d topics", len(topics))
+	}
+
+	id, document, err := Connector{}.Map(topics[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "DC-2" || document.Status != "closed" || document.Link != cfg.DiscourseUrl+"t/cart-is-empty/2" || document.AuthorName != "jane" {
+		t.Errorf("unexpected document %s: %+v", id, document)
+	}
+
+	if !strings.Contains(document.Description, "Clear the cache") || strings.Join(document.Labels, ",") != "checkout,cart" || document.Reactions != 4 {
+		t.Errorf("expected the accepted answer, tags and likes in the document: %+v", document)
+	}
+}
+
+func TestTopicDocumentStatus(t *testing.T) {
+	cases := []struct {
+		topic  Topic
+		status string
+	}{
+		{Topic{}, "open"},
+		{Topic{Solved: true}, "closed"},
+		{Topic{Closed: true}, "closed"},
+	}
+
+	for _, c := range cases {
+		if document := topicDocument(&c.topic); document.Status != c.status {
+			t.Errorf("expected status %s for %+v, got %s", c.status, c.topic, document.Status)
+		}
+	}
+}
+
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(payload))
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhook(t *testing.T) {
+	// CI skips the signature check like the GitHub webhook does.
+	t.Setenv("CI", "false")
+
+	requests, cfg := fakeForum(t)
+	handler := Connector{}.Webhook(cfg)
+
+	send := func(event string, payload string, signature string) int {
+		request := httptest.NewRequest(http.MethodPost, "/webhook/discourse", strings.NewReader(payload))
+		request.Header.Set("X-Discourse-Event", event)
+		request.Header.Set("X-Discourse-Event-Signature", signature)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		return recorder.Code
+	}
+
+	if code := send("topic_created", `{"topic": {"id": 2}}`, "sha256=invalid"); code != http.StatusBadRequest {
+		t.Errorf("expected an invalid signature to be rejected, got %d", code)
+	}
+
+	for event, payload := range map[string]string{
+		"post_edited":     `{"post": {"topic_id": 2}}`,
+		"topic_destroyed": `{"topic": {"id": 3}}`,
+		"topic_edited":    `{"topic": {"id": 4}}`,
+	} {
+		if code := send(event, payload, sign(payload)); code != http.StatusOK {
+			t.Errorf("expected %s to be handled, got %d", event, code)
+		}
+	}
+
+	log := strings.Join(*requests, "\n")
+	for _, expected := range []string{"PUT /issues/_doc/DC-2", "DELETE /issues/_doc/DC-3", "DELETE /issues/_doc/DC-4"} {
+		if !strings.Contains(log, expected) {
+			t.Errorf("expected request %s, requests:\n%s", expected, log)
+		}
+	}
+}
+
+func TestWebhookEvent(t *testing.T) {
+	for header, expected := range map[string]string{"topic_created": "topic_created", "made_up": "other", "": "other"} {
+		request := httptest.NewRequest(http.MethodPost, "/webhook/discourse", nil)
+		request.Header.Set("X-Discourse-Event", header)
+
+		if event := (Connector{}).WebhookEvent(request); event != expected {
+			t.Errorf("expected event %s for header %q, got %s", expected, header, event)
+		}
+	}
+}
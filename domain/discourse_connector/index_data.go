@@ -0,0 +1,252 @@
+package discourse_connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+)
+
+var httpClient = metrics.HTTPClient("discourse")
+
+var errNotFound = errors.New("not found on the forum")
+
+// Topic is a topic of the forum with its first post and accepted answer, as stored by the download command.
+type Topic struct {
+	Id             int       `json:"id"`
+	Title          string    `json:"title"`
+	Url            string    `json:"url"`
+	Body           string    `json:"body"`
+	AcceptedAnswer string    `json:"accepted_answer,omitempty"`
+	Solved         bool      `json:"solved"`
+	Closed         bool      `json:"closed"`
+	Tags           []string  `json:"tags"`
+	LikeCount      int       `json:"like_count"`
+	Author         string    `json:"author"`
+	AuthorUrl      string    `json:"author_url"`
+	CreatedAt      time.Time `json:"created_at"`
+	BumpedAt       time.Time `json:"bumped_at"`
+}
+
+type topicList struct {
+	TopicList struct {
+		MoreTopicsUrl string `json:"more_topics_url"`
+		Topics        []struct {
+			Id       int       `json:"id"`
+			Pinned   bool      `json:"pinned"`
+			BumpedAt time.Time `json:"bumped_at"`
+		} `json:"topics"`
+	} `json:"topic_list"`
+}
+
+// topicView is the response of /t/<id>.json, the accepted answer is added by the discourse-solved plugin.
+type topicView struct {
+	Id         int       `json:"id"`
+	Title      string    `json:"title"`
+	Slug       string    `json:"slug"`
+	Closed     bool      `json:"closed"`
+	Archived   bool      `json:"archived"`
+	Tags       []tag     `json:"tags"`
+	LikeCount  int       `json:"like_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	BumpedAt   time.Time `json:"bumped_at"`
+	PostStream struct {
+		Posts []post `json:"posts"`
+	} `json:"post_stream"`
+	AcceptedAnswer *struct {
+		PostNumber int `json:"post_number"`
+	} `json:"accepted_answer"`
+}
+
+// tag is a plain name in older Discourse versions and an object in newer ones.
+type tag string
+
+func (t *tag) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = tag(name)
+		return nil
+	}
+
+	var object struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+
+	*t = tag(object.Name)
+
+	return nil
+}
+
+func tagNames(tags []tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, string(tag))
+	}
+
+	return names
+}
+
+type post struct {
+	PostNumber int    `json:"post_number"`
+	Username   string `json:"username"`
+	Raw        string `json:"raw"`
+}
+
+func (v topicView) post(number int) *post {
+	for i := range v.PostStream.Posts {
+		if v.PostStream.Posts[i].PostNumber == number {
+			return &v.PostStream.Posts[i]
+		}
+	}
+
+	return nil
+}
+
+// getJson decodes a response of the forum API, it waits for the Retry-After of rate limited requests.
+func getJson(path string, result any, config config.Config, ctx context.Context) error {
+	if config.DiscourseUrl == "" {
+		return errors.New("DISCOURSE_URL is not set")
+	}
+
+	for {
+		requestCtx, cancel := config.WithTimeout(ctx, config.DiscourseTimeout)
+
+		req, err := http.NewRequestWithContext(requestCtx, http.MethodGet, strings.TrimSuffix(config.DiscourseUrl, "/")+path, nil)
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		cancel()
+
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			wait, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			if wait <= 0 {
+				wait = 10
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(wait) * time.Second):
+			}
+		case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden:
+			return errNotFound
+		case resp.StatusCode != http.StatusOK:
+			return fmt.Errorf("request to %s failed with status %d", path, resp.StatusCode)
+		default:
+			return json.Unmarshal(body, result)
+		}
+	}
+}
+
+// getTopic fetches a topic with the raw markdown of its first post and accepted answer. The topics are fetched
+// without credentials, so only public topics are indexed.
+func getTopic(id int, config config.Config, ctx context.Context) (*Topic, error) {
+	var view topicView
+	if err := getJson(fmt.Sprintf("/t/%d.json?include_raw=true", id), &view, config, ctx); err != nil {
+		return nil, fmt.Errorf("failed to fetch topic %d: %w", id, err)
+	}
+
+	baseUrl := strings.TrimSuffix(config.DiscourseUrl, "/")
+
+	topic := &Topic{
+		Id:        view.Id,
+		Title:     view.Title,
+		Url:       fmt.Sprintf("%s/t/%s/%d", baseUrl, view.Slug, view.Id),
+		Solved:    view.AcceptedAnswer != nil,
+		Closed:    view.Closed || view.Archived,
+		Tags:      tagNames(view.Tags),
+		LikeCount: view.LikeCount,
+		CreatedAt: view.CreatedAt,
+		BumpedAt:  view.BumpedAt,
+	}
+
+	if first := view.post(1); first != nil {
+		topic.Body = first.Raw
+		topic.Author = first.Username
+		topic.AuthorUrl = baseUrl + "/u/" + first.Username
+	}
+
+	if view.AcceptedAnswer == nil {
+		return topic, nil
+	}
+
+	// Only the first posts are part of the stream, a later answer is fetched with the posts around it.
+	answer := view.post(view.AcceptedAnswer.PostNumber)
+	if answer == nil {
+		var around topicView
+		if err := getJson(fmt.Sprintf("/t/%d/%d.json?include_raw=true", id, view.AcceptedAnswer.PostNumber), &around, config, ctx); err != nil {
+			return nil, fmt.Errorf("failed to fetch accepted answer of topic %d: %w", id, err)
+		}
+
+		answer = around.post(view.AcceptedAnswer.PostNumber)
+	}
+
+	if answer != nil {
+		topic.AcceptedAnswer = answer.Raw
+	}
+
+	return topic, nil
+}
+
+func topicId(id int) string {
+	return fmt.Sprintf("DC-%d", id)
+}
+
+// topicDocument counts solved and closed topics as closed, the accepted answer is part of the description.
+func topicDocument(topic *Topic) search.Document {
+	state := "open"
+	if topic.Solved || topic.Closed {
+		state = "closed"
+	}
+
+	description := topic.Body
+	if topic.AcceptedAnswer != "" {
+		description += "\n\n" + topic.AcceptedAnswer
+	}
+
+	return search.Document{
+		Title:        topic.Title,
+		Description:  search.CleanupString(description),
+		Status:       state,
+		Type:         "Topic",
+		Link:         topic.Url,
+		ExternalLink: topic.Url,
+		FixVersion:   []string{"n/a"},
+		Public:       true,
+		Source:       "discourse",
+		AuthorName:   topic.Author,
+		AuthorLink:   topic.AuthorUrl,
+		DateCreated:  topic.CreatedAt.Unix(),
+		Labels:       topic.Tags,
+		Reactions:    topic.LikeCount,
+	}
+}
@@ -0,0 +1,255 @@
+package dry_run
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// Canonical strategies pick the document of a cluster the others are closed as duplicate of.
+const (
+	CanonicalOldest    = "oldest"
+	CanonicalReactions = "reactions"
+)
+
+// Cluster is a group of documents connected by candidate pairs, Score is the best score within the cluster.
+type Cluster struct {
+	Canonical Document   `json:"canonical"`
+	Documents []Document `json:"documents"`
+	Pairs     int        `json:"pairs"`
+	Score     float64    `json:"score"`
+}
+
+type ClusterReport struct {
+	Date      int64     `json:"date"`
+	Options   Options   `json:"options"`
+	Canonical string    `json:"canonical"`
+	Documents int       `json:"documents"`
+	Errors    int       `json:"errors"`
+	Clusters  []Cluster `json:"clusters"`
+}
+
+// RunClusters searches the neighbours of every selected document and groups the documents connected by a
+// score of at least MinScore. Only neighbours which are selected by the options themselves join a cluster.
+func RunClusters(options Options, canonical string, config config.Config, ctx context.Context) (ClusterReport, error) {
+	if canonical != CanonicalOldest && canonical != CanonicalReactions {
+		return ClusterReport{}, fmt.Errorf("unknown canonical strategy \"%s\", expected %s or %s", canonical, CanonicalOldest, CanonicalReactions)
+	}
+
+	report, err := Run(options, config, ctx)
+
+	clusterReport := ClusterReport{
+		Date:      report.Date,
+		Options:   options,
+		Canonical: canonical,
+		Documents: report.Documents,
+		Errors:    report.Errors,
+		Clusters:  BuildClusters(lo.Filter(report.Pairs, func(pair Pair, _ int) bool { return options.selects(pair.Match) }), canonical),
+	}
+
+	return clusterReport, err
+}
+
+func (o Options) selects(document Document) bool {
+	if o.Status != "" && document.Status != o.Status {
+		return false
+	}
+
+	if o.Since != 0 && document.DateCreated < o.Since {
+		return false
+	}
+
+	return len(o.Sources) == 0 || lo.Contains(o.Sources, document.Source)
+}
+
+// BuildClusters returns the connected components of the pairs, largest cluster first.
+func BuildClusters(pairs []Pair, canonical string) []Cluster {
+	parent := map[string]string{}
+	documents := map[string]Document{}
+
+	var find func(id string) string
+	find = func(id string) string {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+
+		return parent[id]
+	}
+
+	for _, pair := range pairs {
+		for _, document := range []Document{pair.Document, pair.Match} {
+			if _, found := parent[document.Id]; !found {
+				parent[document.Id] = document.Id
+				documents[document.Id] = document
+			}
+		}
+
+		parent[find(pair.Document.Id)] = find(pair.Match.Id)
+	}
+
+	byRoot := map[string]*Cluster{}
+
+	for id, document := range documents {
+		root := find(id)
+		if byRoot[root] == nil {
+			byRoot[root] = &Cluster{}
+		}
+
+		byRoot[root].Documents = append(byRoot[root].Documents, document)
+	}
+
+	for _, pair := range pairs {
+		cluster := byRoot[find(pair.Document.Id)]
+		cluster.Pairs++
+		cluster.Score = math.Max(cluster.Score, pair.Score)
+	}
+
+	clusters := make([]Cluster, 0, len(byRoot))
+
+	for _, cluster := range byRoot {
+		sortDocuments(cluster.Documents, canonical)
+		cluster.Canonical = cluster.Documents[0]
+
+		clusters = append(clusters, *cluster)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Documents) != len(clusters[j].Documents) {
+			return len(clusters[i].Documents) > len(clusters[j].Documents)
+		}
+
+		if clusters[i].Score != clusters[j].Score {
+			return clusters[i].Score > clusters[j].Score
+		}
+
+		return clusters[i].Canonical.Id < clusters[j].Canonical.Id
+	})
+
+	return clusters
+}
+
+// sortDocuments puts the canonical document first, the most reacted strategy falls back to the oldest document.
+func sortDocuments(documents []Document, canonical string) {
+	sort.Slice(documents, func(i, j int) bool {
+		a, b := documents[i], documents[j]
+
+		if canonical == CanonicalReactions && a.Reactions != b.Reactions {
+			return a.Reactions > b.Reactions
+		}
+
+		// Documents without creation date go last, they can not be the oldest.
+		if a.DateCreated != b.DateCreated {
+			return b.DateCreated == 0 || (a.DateCreated != 0 && a.DateCreated < b.DateCreated)
+		}
+
+		return a.Id < b.Id
+	})
+}
+
+// ClusterFormats are the cluster report formats, see WriteClusters.
+var ClusterFormats = []string{"markdown", "json", "csv"}
+
+func WriteClusters(w io.Writer, format string, report ClusterReport) error {
+	switch format {
+	case "markdown":
+		return WriteClustersMarkdown(w, report)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(report)
+	case "csv":
+		return WriteClustersCSV(w, report)
+	default:
+		return fmt.Errorf("unknown report format \"%s\", expected one of %s", format, strings.Join(ClusterFormats, ", "))
+	}
+}
+
+// WriteClustersMarkdown writes an agenda for triage meetings, one section per cluster.
+func WriteClustersMarkdown(w io.Writer, report ClusterReport) error {
+	var output strings.Builder
+
+	fmt.Fprintf(&output, "# Duplicate clusters\n\n")
+	fmt.Fprintf(&output, "%d clusters among %d documents, minimum score %.2f, canonical issue is the %s one.\n",
+		len(report.Clusters), report.Documents, report.Options.MinScore, canonicalLabel(report.Canonical))
+
+	for i, cluster := range report.Clusters {
+		fmt.Fprintf(&output, "\n## %d. %s\n\n", i+1, cluster.Canonical.Title)
+		fmt.Fprintf(&output, "%d documents, %d pairs, best score %.3f\n\n", len(cluster.Documents), cluster.Pairs, cluster.Score)
+
+		for _, document := range cluster.Documents {
+			marker := ""
+			if document.Id == cluster.Canonical.Id {
+				marker = " **(canonical)**"
+			}
+
+			fmt.Fprintf(&output, "- [%s](%s) %s%s%s\n", document.Id, document.Link, document.Title, describeDocument(document), marker)
+		}
+	}
+
+	_, err := io.WriteString(w, output.String())
+
+	return err
+}
+
+func canonicalLabel(canonical string) string {
+	if canonical == CanonicalReactions {
+		return "most reacted"
+	}
+
+	return "oldest"
+}
+
+func describeDocument(document Document) string {
+	var details []string
+
+	if document.Status != "" {
+		details = append(details, document.Status)
+	}
+
+	if document.DateCreated != 0 {
+		details = append(details, "created "+time.Unix(document.DateCreated, 0).UTC().Format("2006-01-02"))
+	}
+
+	if document.Reactions != 0 {
+		details = append(details, fmt.Sprintf("%d reactions", document.Reactions))
+	}
+
+	if len(details) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(details, ", ") + ")"
+}
+
+// WriteClustersCSV writes one row per document, so the clusters can be filtered in a spreadsheet.
+func WriteClustersCSV(w io.Writer, report ClusterReport) error {
+	writer := csv.NewWriter(w)
+
+	rows := [][]string{{"cluster", "canonical", "id", "title", "status", "source", "link", "date_created", "reactions", "cluster_score"}}
+
+	for i, cluster := range report.Clusters {
+		for _, document := range cluster.Documents {
+			rows = append(rows, []string{
+				strconv.Itoa(i + 1),
+				strconv.FormatBool(document.Id == cluster.Canonical.Id),
+				document.Id, document.Title, document.Status, document.Source, document.Link,
+				strconv.FormatInt(document.DateCreated, 10),
+				strconv.Itoa(document.Reactions),
+				strconv.FormatFloat(cluster.Score, 'f', 3, 64),
+			})
+		}
+	}
+
+	return writer.WriteAll(rows)
+}
@@ -0,0 +1,277 @@
+// Package dry_run searches duplicates for documents which are already indexed, without commenting on them.
+package dry_run
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+)
+
+const (
+	// scrollPageSize is the number of documents fetched per scroll request.
+	scrollPageSize  = 500
+	scrollKeepAlive = time.Minute
+)
+
+// Options select the documents to check, an empty status or source list checks all documents.
+type Options struct {
+	Sources  []string `json:"sources"`
+	Status   string   `json:"status"`
+	MinScore float64  `json:"minScore"`
+	// Size is the number of hits per document which are checked against MinScore.
+	Size int `json:"size"`
+	// Since is the unix time from which on documents were created, zero checks documents of any age.
+	Since int64 `json:"since,omitempty"`
+}
+
+// DefaultOptions checks the open GitHub issues like the bot does for new issues.
+func DefaultOptions() Options {
+	return Options{Sources: []string{"github"}, Status: "open", MinScore: 2.0, Size: 5}
+}
+
+type Document struct {
+	Id          string `json:"id"`
+	Title       string `json:"title"`
+	Status      string `json:"status"`
+	Source      string `json:"source"`
+	Link        string `json:"link"`
+	DateCreated int64  `json:"dateCreated,omitempty"`
+	Reactions   int    `json:"reactions,omitempty"`
+}
+
+// Pair is a candidate duplicate, the score is the confidence of the search.
+type Pair struct {
+	Document Document `json:"document"`
+	Match    Document `json:"match"`
+	Score    float64  `json:"score"`
+}
+
+type Report struct {
+	Date      int64   `json:"date"`
+	Options   Options `json:"options"`
+	Documents int     `json:"documents"`
+	Errors    int     `json:"errors"`
+	// Pairs are sorted by descending score, every pair is listed once even if both documents were checked.
+	Pairs []Pair `json:"pairs"`
+}
+
+// Run searches duplicates for every selected document, failed searches are logged and counted.
+func Run(options Options, config config.Config, ctx context.Context) (Report, error) {
+	logger := logging.FromContext(ctx)
+
+	report := Report{Date: time.Now().Unix(), Options: options, Pairs: []Pair{}}
+
+	documents, err := scroll(options, config, ctx)
+	if err != nil {
+		return report, err
+	}
+
+	report.Documents = len(documents)
+
+	var lock sync.Mutex
+
+	search.Parallel(len(documents), func(i int) {
+		document := documents[i]
+
+		result, err := search.Search(
+			document.Source.Title,
+			document.Source.Description,
+			search.SearchFilter{ExcludedDocumentId: document.ID, MinScore: options.MinScore, Size: options.Size},
+			config,
+			ctx)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		if err != nil {
+			logger.Errorf("Dry run search for %s failed: %s", document.ID, err)
+			report.Errors++
+			return
+		}
+
+		for _, hit := range result.Hits.Hits {
+			report.Pairs = append(report.Pairs, Pair{Document: toDocument(document, config), Match: toDocument(hit, config), Score: hit.Score})
+		}
+	})
+
+	report.Pairs = uniquePairs(report.Pairs)
+
+	if report.Errors > 0 && report.Errors == report.Documents {
+		return report, fmt.Errorf("all %d searches failed", report.Errors)
+	}
+
+	return report, nil
+}
+
+// scroll fetches all selected documents, a plain search would stop at the result window of the index.
+func scroll(options Options, config config.Config, ctx context.Context) ([]search.IssueResult, error) {
+	size := scrollPageSize
+
+	req := opensearchapi.SearchRequest{
+		Index:  []string{config.IndexName},
+		Body:   strings.NewReader(documentQuery(options)),
+		Size:   &size,
+		Scroll: scrollKeepAlive,
+		Source: []string{"title", "description", "status", "source", "link", "dateCreated", "reactions"},
+	}
+
+	page, err := scrollPage(req.Do, config, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The scroll id can change between pages, only the last one has to be cleared.
+	scrollId := page.ScrollId
+	defer func() {
+		clearScroll(scrollId, config, ctx)
+	}()
+
+	var documents []search.IssueResult
+
+	for len(page.Hits.Hits) > 0 {
+		documents = append(documents, page.Hits.Hits...)
+
+		next := opensearchapi.ScrollRequest{ScrollID: scrollId, Scroll: scrollKeepAlive}
+
+		if page, err = scrollPage(next.Do, config, ctx); err != nil {
+			return nil, err
+		}
+
+		if page.ScrollId != "" {
+			scrollId = page.ScrollId
+		}
+	}
+
+	return documents, nil
+}
+
+type scrollResponse struct {
+	ScrollId string `json:"_scroll_id"`
+	search.SearchResponse
+}
+
+func scrollPage(do func(ctx context.Context, transport opensearchapi.Transport) (*opensearchapi.Response, error), config config.Config, ctx context.Context) (*scrollResponse, error) {
+	ctx, cancel := config.WithTimeout(ctx, config.SearchTimeout)
+	defer cancel()
+
+	resp, err := do(ctx, config.OpensearchClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scroll documents: %w", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("failed to scroll documents: %s", body)
+	}
+
+	var page scrollResponse
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode opensearch response: %w", err)
+	}
+
+	return &page, nil
+}
+
+// clearScroll frees the search context right away instead of waiting for the keep alive.
+func clearScroll(scrollId string, config config.Config, ctx context.Context) {
+	if scrollId == "" {
+		return
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.SearchTimeout)
+	defer cancel()
+
+	req := opensearchapi.ClearScrollRequest{ScrollID: []string{scrollId}}
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("Failed to clear scroll: %s", err)
+		return
+	}
+
+	resp.Body.Close()
+}
+
+func documentQuery(options Options) string {
+	var filters []string
+
+	if options.Status != "" {
+		status, _ := json.Marshal(options.Status)
+		filters = append(filters, fmt.Sprintf(`{ "term": { "status.keyword": %s }}`, status))
+	}
+
+	if len(options.Sources) > 0 {
+		sources, _ := json.Marshal(options.Sources)
+		filters = append(filters, fmt.Sprintf(`{ "terms": { "source.keyword": %s }}`, sources))
+	}
+
+	if options.Since != 0 {
+		filters = append(filters, fmt.Sprintf(`{ "range": { "dateCreated": { "gte": %d }}}`, options.Since))
+	}
+
+	return fmt.Sprintf(`{ "sort": ["_doc"], "query": { "bool": { "filter": [%s] }}}`, strings.Join(filters, ","))
+}
+
+func toDocument(hit search.IssueResult, config config.Config) Document {
+	document := Document{
+		Id:          hit.ID,
+		Title:       hit.Source.Title,
+		Status:      hit.Source.Status,
+		Source:      hit.Source.Source,
+		Link:        hit.Source.Link,
+		DateCreated: hit.Source.DateCreated,
+		Reactions:   hit.Source.Reactions,
+	}
+
+	if document.Link == "" && document.Source == "jira" {
+		document.Link = strings.TrimSuffix(config.JiraUrl, "/") + "/browse/" + hit.ID
+	}
+
+	return document
+}
+
+// uniquePairs keeps the best score of pairs found from both sides and sorts them by descending score.
+func uniquePairs(pairs []Pair) []Pair {
+	best := map[[2]string]int{}
+	unique := []Pair{}
+
+	for _, pair := range pairs {
+		key := [2]string{pair.Document.Id, pair.Match.Id}
+		if key[0] > key[1] {
+			key[0], key[1] = key[1], key[0]
+		}
+
+		if i, found := best[key]; found {
+			if pair.Score > unique[i].Score {
+				unique[i] = pair
+			}
+
+			continue
+		}
+
+		best[key] = len(unique)
+		unique = append(unique, pair)
+	}
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		if unique[i].Score != unique[j].Score {
+			return unique[i].Score > unique[j].Score
+		}
+
+		return unique[i].Document.Id < unique[j].Document.Id
+	})
+
+	return unique
+}
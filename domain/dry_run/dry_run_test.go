@@ -0,0 +1,204 @@
+package dry_run
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+func hit(id string, score float64, title string, source string) string {
+	return fmt.Sprintf(`{"_id": "%s", "_score": %f, "_source": {"title": "%s", "description": "%s description", "status": "open", "source": "%s", "link": "https://example.com/%s"}}`, id, score, title, title, source, id)
+}
+
+func fakeOpensearch(t *testing.T) (config.Config, *[]string) {
+	t.Helper()
+
+	var lock sync.Mutex
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/issues/_search" && r.URL.Query().Get("scroll") != "":
+			fmt.Fprintf(w, `{"_scroll_id": "page-1", "hits": {"hits": [%s, %s]}}`, hit("GH-1", 0, "Paging broken", "github"), hit("GH-2", 0, "Paging fails", "github"))
+		case r.URL.Path == "/_search/scroll" && r.Method == http.MethodDelete:
+			fmt.Fprint(w, `{"succeeded": true}`)
+		case r.URL.Path == "/_search/scroll":
+			fmt.Fprint(w, `{"_scroll_id": "page-2", "hits": {"hits": []}}`)
+		case r.URL.Path == "/issues/_search" && strings.Contains(string(body), `"GH-1"`):
+			fmt.Fprintf(w, `{"hits": {"hits": [%s, %s]}}`, hit("GH-2", 2.5, "Paging fails", "github"), hit("NEXT-3", 2.1, "Pagination", "jira"))
+		case r.URL.Path == "/issues/_search":
+			fmt.Fprintf(w, `{"hits": {"hits": [%s]}}`, hit("GH-1", 2.4, "Paging broken", "github"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := opensearch.NewClient(opensearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return config.Config{IndexName: "issues", ModelId: "model", OpensearchClient: client}, &requests
+}
+
+func TestRun(t *testing.T) {
+	cfg, requests := fakeOpensearch(t)
+
+	report, err := Run(DefaultOptions(), cfg, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Documents != 2 || report.Errors != 0 || len(report.Pairs) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// GH-1 and GH-2 found each other, only the better score is kept.
+	first := report.Pairs[0]
+	if first.Document.Id != "GH-1" || first.Match.Id != "GH-2" || first.Score != 2.5 || first.Match.Status != "open" {
+		t.Errorf("unexpected first pair: %+v", first)
+	}
+
+	if report.Pairs[1].Match.Id != "NEXT-3" || report.Pairs[1].Match.Link != "https://example.com/NEXT-3" {
+		t.Errorf("unexpected second pair: %+v", report.Pairs[1])
+	}
+
+	if !strings.Contains(strings.Join(*requests, "\n"), "DELETE /_search/scroll") {
+		t.Errorf("expected the scroll to be cleared, requests: %v", *requests)
+	}
+}
+
+func TestDocumentQuery(t *testing.T) {
+	query := documentQuery(Options{Sources: []string{"github", "jira"}, Status: "open"})
+	if !strings.Contains(query, `"terms": { "source.keyword": ["github","jira"] }`) || !strings.Contains(query, `"term": { "status.keyword": "open" }`) {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	if query := documentQuery(Options{}); !strings.Contains(query, `"filter": []`) {
+		t.Errorf("expected no filters, got %s", query)
+	}
+
+	if query := documentQuery(Options{Since: 1700000000}); !strings.Contains(query, `"range": { "dateCreated": { "gte": 1700000000 }}`) {
+		t.Errorf("expected a creation date filter, got %s", query)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	report := Report{Documents: 1, Pairs: []Pair{{
+		Document: Document{Id: "GH-1", Title: "Paging, broken", Link: "https://github.com/shopware/platform/issues/1"},
+		Match:    Document{Id: "NEXT-2", Title: "<b>Paging</b>", Link: "https://shopware.atlassian.net/browse/NEXT-2"},
+		Score:    2.34567,
+	}}}
+
+	var buffer bytes.Buffer
+	if err := Write(&buffer, FormatOf("report.csv"), report); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 || rows[1][0] != "2.346" || rows[1][2] != "Paging, broken" || rows[1][6] != "NEXT-2" {
+		t.Errorf("unexpected csv: %v", rows)
+	}
+
+	buffer.Reset()
+	if err := Write(&buffer, FormatOf("report.HTML"), report); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buffer.String(), "&lt;b&gt;Paging&lt;/b&gt;") || !strings.Contains(buffer.String(), `href="https://shopware.atlassian.net/browse/NEXT-2"`) {
+		t.Errorf("unexpected html:\n%s", buffer.String())
+	}
+
+	if err := Write(&buffer, "xml", report); err == nil {
+		t.Error("expected unknown format to fail")
+	}
+}
+
+func TestBuildClusters(t *testing.T) {
+	a := Document{Id: "GH-1", Title: "Paging broken", DateCreated: 300, Reactions: 1}
+	b := Document{Id: "GH-2", Title: "Paging fails", DateCreated: 100}
+	c := Document{Id: "GH-3", Title: "Pagination", DateCreated: 200, Reactions: 5}
+	d := Document{Id: "SO-4", Title: "Cache warmup"}
+	e := Document{Id: "GH-5", Title: "Cache warmup fails", DateCreated: 400}
+
+	pairs := []Pair{
+		{Document: a, Match: b, Score: 2.5},
+		{Document: c, Match: b, Score: 2.2},
+		{Document: d, Match: e, Score: 3.1},
+	}
+
+	clusters := BuildClusters(pairs, CanonicalOldest)
+	if len(clusters) != 2 || len(clusters[0].Documents) != 3 || clusters[0].Pairs != 2 || clusters[0].Score != 2.5 {
+		t.Fatalf("unexpected clusters: %+v", clusters)
+	}
+
+	if clusters[0].Canonical.Id != "GH-2" || clusters[0].Documents[1].Id != "GH-3" {
+		t.Errorf("expected the oldest issue to be canonical, got %+v", clusters[0])
+	}
+
+	// SO-4 has no creation date, so it can not be the oldest.
+	if clusters[1].Canonical.Id != "GH-5" {
+		t.Errorf("expected GH-5 to be canonical, got %+v", clusters[1])
+	}
+
+	if canonical := BuildClusters(pairs, CanonicalReactions)[0].Canonical.Id; canonical != "GH-3" {
+		t.Errorf("expected the most reacted issue to be canonical, got %s", canonical)
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteClusters(&buffer, "markdown", ClusterReport{Canonical: CanonicalOldest, Clusters: clusters}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buffer.String(), "## 1. Paging fails") || !strings.Contains(buffer.String(), "- [GH-2]() Paging fails (created 1970-01-01) **(canonical)**") {
+		t.Errorf("unexpected markdown:\n%s", buffer.String())
+	}
+}
+
+func TestToDocumentLinksJiraIssues(t *testing.T) {
+	hit := search.IssueResult{ID: "NEXT-7", Source: search.Document{Source: "jira"}}
+
+	if document := toDocument(hit, config.Config{JiraUrl: "https://jira.example.com/"}); document.Link != "https://jira.example.com/browse/NEXT-7" {
+		t.Errorf("expected a link to the configured Jira, got %s", document.Link)
+	}
+}
+
+func TestOptionsSelects(t *testing.T) {
+	options := Options{Sources: []string{"github"}, Status: "open"}
+
+	if !options.selects(Document{Source: "github", Status: "open"}) || options.selects(Document{Source: "github", Status: "closed"}) || options.selects(Document{Source: "jira", Status: "open"}) {
+		t.Error("unexpected selection")
+	}
+
+	if !(Options{}).selects(Document{Source: "jira", Status: "closed"}) {
+		t.Error("expected empty options to select every document")
+	}
+
+	if since := (Options{Since: 100}); since.selects(Document{DateCreated: 99}) || !since.selects(Document{DateCreated: 100}) {
+		t.Error("expected documents created before since to be skipped")
+	}
+}
@@ -0,0 +1,127 @@
+package dry_run
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats are the report formats, see Write.
+var Formats = []string{"csv", "json", "html"}
+
+// FormatOf derives the format from the file extension, it defaults to csv.
+func FormatOf(path string) string {
+	extension := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if extension == "htm" {
+		return "html"
+	}
+
+	for _, format := range Formats {
+		if extension == format {
+			return format
+		}
+	}
+
+	return "csv"
+}
+
+func Write(w io.Writer, format string, report Report) error {
+	switch format {
+	case "csv":
+		return WriteCSV(w, report)
+	case "json":
+		return WriteJSON(w, report)
+	case "html":
+		return WriteHTML(w, report)
+	default:
+		return fmt.Errorf("unknown report format \"%s\", expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// WriteCSV writes one row per pair, best score first.
+func WriteCSV(w io.Writer, report Report) error {
+	writer := csv.NewWriter(w)
+
+	rows := [][]string{{"score", "id", "title", "status", "source", "link", "match_id", "match_title", "match_status", "match_source", "match_link"}}
+
+	for _, pair := range report.Pairs {
+		rows = append(rows, []string{
+			strconv.FormatFloat(pair.Score, 'f', 3, 64),
+			pair.Document.Id, pair.Document.Title, pair.Document.Status, pair.Document.Source, pair.Document.Link,
+			pair.Match.Id, pair.Match.Title, pair.Match.Status, pair.Match.Source, pair.Match.Link,
+		})
+	}
+
+	return writer.WriteAll(rows)
+}
+
+func WriteJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date": func(unix int64) string { return time.Unix(unix, 0).UTC().Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Dry run report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: .4em; text-align: left; vertical-align: top; }
+th { cursor: pointer; background: #f5f5f5; }
+td.score { text-align: right; font-variant-numeric: tabular-nums; }
+</style>
+</head>
+<body>
+<h1>Dry run report</h1>
+<p>{{ len .Pairs }} candidate pairs among {{ .Documents }} documents, {{ .Errors }} failed searches, minimum score {{ .Options.MinScore }}, created {{ date .Date }}.</p>
+<table id="pairs">
+<thead>
+<tr><th data-type="number">Score</th><th>Document</th><th>Status</th><th>Match</th><th>Status</th></tr>
+</thead>
+<tbody>
+{{- range .Pairs }}
+<tr>
+<td class="score">{{ printf "%.3f" .Score }}</td>
+<td><a href="{{ .Document.Link }}">{{ .Document.Id }}</a> {{ .Document.Title }}</td>
+<td>{{ .Document.Status }}</td>
+<td><a href="{{ .Match.Link }}">{{ .Match.Id }}</a> {{ .Match.Title }}</td>
+<td>{{ .Match.Status }}</td>
+</tr>
+{{- end }}
+</tbody>
+</table>
+<script>
+document.querySelectorAll('#pairs th').forEach((header, column) => {
+  let descending = column === 0;
+  header.addEventListener('click', () => {
+    descending = !descending;
+    const body = document.querySelector('#pairs tbody');
+    const value = row => row.children[column].textContent.trim();
+    const rows = [...body.rows].sort((a, b) => header.dataset.type === 'number'
+      ? parseFloat(value(a)) - parseFloat(value(b))
+      : value(a).localeCompare(value(b)));
+    if (descending) rows.reverse();
+    body.append(...rows);
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// WriteHTML writes a standalone page, the table can be sorted by clicking a column.
+func WriteHTML(w io.Writer, report Report) error {
+	return htmlReport.Execute(w, report)
+}
@@ -0,0 +1,153 @@
+package github_connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+)
+
+type Action string
+
+// The bot comments start with these prefixes, so /dedupe not-duplicate can find them again.
+const (
+	suggestionsPrefix = "We found the following existing issues which may help or are related to your topic: \n"
+	duplicatePrefix   = "This issue might be a duplicate of "
+)
+
+const (
+	ActionNone    Action = "none"
+	ActionComment Action = "comment"
+	// ActionLabel applies the duplicate label and comments with the top hit.
+	ActionLabel Action = "label"
+)
+
+// Plan is what the bot does on a new issue, depending on the confidence band of the best hit.
+type Plan struct {
+	Issue   int
+	Action  Action
+	Score   float64
+	TopHit  string
+	Label   string
+	Comment string
+}
+
+// PlanActions picks the action for the hits of a new issue, the hits are already above GITHUB_COMMENT_MIN_SCORE.
+// The top hit needs GITHUB_LABEL_MIN_SCORE for the label, only hits which can be linked are considered.
+func PlanActions(issue int, hits []search.IssueResult, config config.Config) Plan {
+	plan := Plan{Issue: issue, Action: ActionNone}
+
+	var linked []search.IssueResult
+	for _, hit := range hits {
+		if hitLink(hit) != "" {
+			linked = append(linked, hit)
+		}
+	}
+
+	if len(linked) == 0 {
+		return plan
+	}
+
+	top := linked[0]
+	plan.Score = top.Score
+	plan.TopHit = top.ID
+
+	var output strings.Builder
+	var ids []string
+
+	if config.GithubLabelMinScore > 0 && top.Score >= config.GithubLabelMinScore {
+		plan.Action = ActionLabel
+		plan.Label = config.GithubDuplicateLabel
+
+		output.WriteString(fmt.Sprintf("%s[%s](%s).\n", duplicatePrefix, top.Source.Title, hitLink(top)))
+
+		if len(linked) > 1 {
+			output.WriteString("\nOther existing issues which may help or are related to your topic: \n")
+		}
+
+		ids = append(ids, top.ID)
+		linked = linked[1:]
+	} else {
+		plan.Action = ActionComment
+
+		output.WriteString(suggestionsPrefix)
+	}
+
+	for _, hit := range linked {
+		output.WriteString(fmt.Sprintf("- [%s](%s)\n", hit.Source.Title, hitLink(hit)))
+		ids = append(ids, hit.ID)
+	}
+
+	output.WriteString(hitIdsComment(ids))
+
+	plan.Comment = output.String()
+
+	return plan
+}
+
+// hitIdsComment lists the ids of the hits in a bot comment, top hit first, for /dedupe not-duplicate. The links
+// are not enough, discussions and forum topics are linked with the URL of their source.
+func hitIdsComment(ids []string) string {
+	return fmt.Sprintf("<!-- %s%s -->\n", hitIdsPrefix, strings.Join(ids, ","))
+}
+
+// hitLink is empty for sources which are not linked in comments.
+func hitLink(hit search.IssueResult) string {
+	switch hit.Source.Source {
+	case "jira":
+		return "https://issues.shopware.com/issues/" + hit.ID
+	case "github":
+		return "https://github.com/shopware/platform/issues/" + strings.Replace(hit.ID, "GH-", "", 1)
+	case discussionsSource, "discourse":
+		return hit.Source.Link
+	default:
+		return ""
+	}
+}
+
+// ExecutePlan comments and labels the issue, with GITHUB_ACTIONS_DRY_RUN it only logs what it would do.
+func ExecutePlan(plan Plan, config config.Config, ctx context.Context) (err error) {
+	logger := logging.FromContext(ctx).With(
+		"issue", plan.Issue,
+		"action", plan.Action,
+		"score", plan.Score,
+		"topHit", plan.TopHit,
+		"dryRun", config.GithubActionsDryRun,
+	)
+
+	defer func() {
+		metrics.GithubActions.WithLabelValues(string(plan.Action), strconv.FormatBool(config.GithubActionsDryRun), metrics.Outcome(err)).Inc()
+	}()
+
+	if plan.Action == ActionNone {
+		logger.Debugf("No action for issue GH-%d", plan.Issue)
+		return nil
+	}
+
+	if config.GithubActionsDryRun {
+		logger.Infof("Dry run, would %s issue GH-%d:\n%s", plan.Action, plan.Issue, plan.Comment)
+		return nil
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.GithubTimeout)
+	defer cancel()
+
+	if plan.Action == ActionLabel {
+		if _, _, err := config.GithubClient.Issues.AddLabelsToIssue(ctx, owner, repository, plan.Issue, []string{plan.Label}); err != nil {
+			return fmt.Errorf("failed to label issue GH-%d: %w", plan.Issue, err)
+		}
+	}
+
+	if err := comment(plan.Issue, plan.Comment, config, ctx); err != nil {
+		return err
+	}
+
+	logger.Infof("Applied action %s to issue GH-%d", plan.Action, plan.Issue)
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package github_connector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+func hits(scores ...float64) []search.IssueResult {
+	var results []search.IssueResult
+
+	results = append(results, search.IssueResult{ID: "SO-9", Score: 5, Source: search.Document{Title: "Question", Source: "stack-overflow"}})
+
+	for i, score := range scores {
+		id := "GH-" + string(rune('1'+i))
+		results = append(results, search.IssueResult{ID: id, Score: score, Source: search.Document{Title: "Issue " + id, Source: "github"}})
+	}
+
+	return results
+}
+
+func discussionHit(id string, score float64) search.IssueResult {
+	return search.IssueResult{ID: id, Score: score, Source: search.Document{
+		Title:  "Discussion " + id,
+		Source: discussionsSource,
+		Link:   "https://github.com/shopware/shopware/discussions/" + strings.TrimPrefix(id, "GHD-"),
+	}}
+}
+
+func TestPlanActions(t *testing.T) {
+	cfg := config.Config{GithubLabelMinScore: 2.5, GithubDuplicateLabel: "possible-duplicate"}
+
+	plan := PlanActions(10, hits(2.7, 2.0), cfg)
+	if plan.Action != ActionLabel || plan.Label != "possible-duplicate" || plan.TopHit != "GH-1" || plan.Score != 2.7 {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+
+	if !strings.HasPrefix(plan.Comment, "This issue might be a duplicate of [Issue GH-1](https://github.com/shopware/platform/issues/1).") || !strings.Contains(plan.Comment, "- [Issue GH-2](") {
+		t.Errorf("unexpected comment:\n%s", plan.Comment)
+	}
+
+	if plan := PlanActions(10, hits(2.4, 2.0), cfg); plan.Action != ActionComment || plan.Label != "" || !strings.Contains(plan.Comment, "- [Issue GH-1](") {
+		t.Errorf("expected a comment below the label threshold, got %+v", plan)
+	}
+
+	if plan := PlanActions(10, hits(2.7), config.Config{}); plan.Action != ActionComment {
+		t.Errorf("expected labelling to be disabled without threshold, got %+v", plan)
+	}
+
+	if plan := PlanActions(10, hits(), cfg); plan.Action != ActionNone {
+		t.Errorf("expected no action without linkable hits, got %+v", plan)
+	}
+}
+
+func TestExecutePlanDryRun(t *testing.T) {
+	// Without GitHub client the dry run would panic if it called the API.
+	cfg := config.Config{GithubLabelMinScore: 2.5, GithubActionsDryRun: true}
+
+	if err := ExecutePlan(PlanActions(10, hits(2.7), cfg), cfg, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
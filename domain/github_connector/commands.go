@@ -0,0 +1,368 @@
+package github_connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/shopwarelabs/jira-issue-bot/domain/judgment"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+)
+
+const commandPrefix = "/dedupe"
+
+const hitIdsPrefix = "dedupe-hits: "
+
+const commandUsage = "Usage: `/dedupe search`, `/dedupe duplicate-of #123` or `/dedupe not-duplicate [#123]`"
+
+var (
+	issueReferencePattern = regexp.MustCompile(`^(?:#|GH-|https://github\.com/` + owner + `/` + repository + `/issues/)(\d+)$`)
+	// hitIdsPattern finds the hit ids of a bot comment, see hitIdsComment.
+	hitIdsPattern = regexp.MustCompile(`<!-- ` + hitIdsPrefix + `([^ ]*) -->`)
+	// hitLinkPattern finds the hits linked in bot comments which were posted before the hit ids were added.
+	hitLinkPattern = regexp.MustCompile(`https://github\.com/` + owner + `/` + repository + `/issues/(\d+)|https://issues\.shopware\.com/issues/([A-Z][A-Z0-9]*-\d+)`)
+)
+
+var errNoPermission = errors.New("only users with write permission can use /dedupe commands")
+
+// Command is a /dedupe line of an issue comment, Issue is the referenced issue number if any.
+type Command struct {
+	Name  string
+	Issue int
+}
+
+// ParseCommand returns false if no line of the comment starts with /dedupe.
+func ParseCommand(body string) (Command, bool) {
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != commandPrefix {
+			continue
+		}
+
+		command := Command{Name: "help"}
+		if len(fields) > 1 {
+			command.Name = strings.ToLower(fields[1])
+		}
+
+		if len(fields) > 2 {
+			match := issueReferencePattern.FindStringSubmatch(fields[2])
+			if match == nil {
+				return Command{Name: "help"}, true
+			}
+
+			// The number can still overflow an int.
+			if _, err := fmt.Sscan(match[1], &command.Issue); err != nil {
+				return Command{Name: "help"}, true
+			}
+		}
+
+		return command, true
+	}
+
+	return Command{}, false
+}
+
+// HandleGithubCommentEvent runs the /dedupe commands of maintainers on issues.
+func HandleGithubCommentEvent(event *github.IssueCommentEvent, config config.Config, ctx context.Context) error {
+	if event.GetAction() != "created" || event.GetIssue().IsPullRequest() || event.GetComment().GetUser().GetType() == "Bot" {
+		return nil
+	}
+
+	command, found := ParseCommand(event.GetComment().GetBody())
+	if !found {
+		return nil
+	}
+
+	issue := event.GetIssue().GetNumber()
+	author := event.GetComment().GetUser().GetLogin()
+
+	logger := logging.FromContext(ctx).With("issue", issue, "command", command.Name, "author", author)
+	ctx = logging.WithLogger(ctx, logger)
+
+	err := runCommand(command, event, config, ctx)
+
+	reaction := "+1"
+	if err != nil {
+		reaction = "confused"
+		logger.Warnf("Command /dedupe %s on issue GH-%d failed: %s", command.Name, issue, err)
+	}
+
+	reactCtx, cancel := config.WithTimeout(ctx, config.GithubTimeout)
+	defer cancel()
+
+	if _, _, reactErr := config.GithubClient.Reactions.CreateIssueCommentReaction(reactCtx, owner, repository, event.GetComment().GetID(), reaction); reactErr != nil {
+		logger.Errorf("Failed to react to command: %s", reactErr)
+	}
+
+	// A maintainer typo is answered with the reaction, only failures of GitHub or OpenSearch fail the webhook.
+	if errors.Is(err, errNoPermission) || errors.Is(err, errUsage) {
+		return nil
+	}
+
+	return err
+}
+
+var errUsage = errors.New("invalid command")
+
+func runCommand(command Command, event *github.IssueCommentEvent, config config.Config, ctx context.Context) error {
+	issue := event.GetIssue().GetNumber()
+	author := event.GetComment().GetUser().GetLogin()
+
+	if err := requireWritePermission(author, config, ctx); err != nil {
+		return err
+	}
+
+	switch {
+	case command.Name == "search" && command.Issue == 0:
+		return searchAgain(event.GetIssue(), config, ctx)
+	case command.Name == "duplicate-of" && command.Issue != 0 && command.Issue != issue:
+		return closeAsDuplicate(issue, command.Issue, author, config, ctx)
+	case command.Name == "not-duplicate":
+		return markNotDuplicate(issue, command.Issue, author, config, ctx)
+	default:
+		if err := comment(issue, commandUsage, config, ctx); err != nil {
+			return err
+		}
+
+		return errUsage
+	}
+}
+
+func requireWritePermission(user string, config config.Config, ctx context.Context) error {
+	ctx, cancel := config.WithTimeout(ctx, config.GithubTimeout)
+	defer cancel()
+
+	level, _, err := config.GithubClient.Repositories.GetPermissionLevel(ctx, owner, repository, user)
+	if err != nil {
+		return fmt.Errorf("failed to fetch permission of %s: %w", user, err)
+	}
+
+	// Maintainers have the write level, the REST API reports maintain as write.
+	if level.GetPermission() != "admin" && level.GetPermission() != "write" {
+		return errNoPermission
+	}
+
+	return nil
+}
+
+func searchAgain(issue *github.Issue, config config.Config, ctx context.Context) error {
+	hits, err := searchIssue(issue, config, ctx)
+	if err != nil {
+		return err
+	}
+
+	plan := PlanActions(issue.GetNumber(), hits, config)
+	if plan.Action == ActionNone {
+		return comment(issue.GetNumber(), "We did not find any existing issues related to your topic.", config, ctx)
+	}
+
+	return ExecutePlan(plan, config, ctx)
+}
+
+func closeAsDuplicate(issue int, original int, author string, config config.Config, ctx context.Context) error {
+	if err := requireIssue(original, config, ctx); err != nil {
+		return err
+	}
+
+	if err := judgment.Record(judgment.Judgment{
+		DocumentId:  fmt.Sprintf("GH-%d", issue),
+		CandidateId: fmt.Sprintf("GH-%d", original),
+		Duplicate:   true,
+		Author:      author,
+		Origin:      "github",
+	}, config, ctx); err != nil {
+		return err
+	}
+
+	if err := comment(issue, fmt.Sprintf("Duplicate of #%d", original), config, ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.GithubTimeout)
+	defer cancel()
+
+	state, reason := "closed", "duplicate"
+	if _, _, err := config.GithubClient.Issues.Edit(ctx, owner, repository, issue, &github.IssueRequest{State: &state, StateReason: &reason}); err != nil {
+		return fmt.Errorf("failed to close issue GH-%d: %w", issue, err)
+	}
+
+	return nil
+}
+
+// requireIssue fails with errUsage if the number is no issue of the repository, e.g. a typo or a pull request.
+func requireIssue(number int, config config.Config, ctx context.Context) error {
+	ctx, cancel := config.WithTimeout(ctx, config.GithubTimeout)
+	defer cancel()
+
+	issue, response, err := config.GithubClient.Issues.Get(ctx, owner, repository, number)
+	if response != nil && (response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone) {
+		return fmt.Errorf("%w: issue #%d does not exist", errUsage, number)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to fetch issue GH-%d: %w", number, err)
+	}
+
+	if issue.IsPullRequest() {
+		return fmt.Errorf("%w: #%d is a pull request", errUsage, number)
+	}
+
+	return nil
+}
+
+// markNotDuplicate records negative judgments for the given issue or all hits of the bot comments, then hides
+// the bot comments. The duplicate label is only removed if the labelled top hit was rejected.
+func markNotDuplicate(issue int, candidate int, author string, config config.Config, ctx context.Context) error {
+	botComments, err := listBotComments(issue, config, ctx)
+	if err != nil {
+		return err
+	}
+
+	var candidates []string
+	if candidate != 0 {
+		candidates = []string{fmt.Sprintf("GH-%d", candidate)}
+	} else {
+		for _, botComment := range botComments {
+			candidates = append(candidates, linkedHits(botComment.GetBody())...)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return fmt.Errorf("%w: no suggested issue to mark, name it like /dedupe not-duplicate #123", errUsage)
+	}
+
+	for _, candidateId := range candidates {
+		if err := judgment.Record(judgment.Judgment{
+			DocumentId:  fmt.Sprintf("GH-%d", issue),
+			CandidateId: candidateId,
+			Duplicate:   false,
+			Author:      author,
+			Origin:      "github",
+		}, config, ctx); err != nil {
+			return err
+		}
+	}
+
+	// Only hide the suggestions if all of them were rejected.
+	if candidate == 0 {
+		for _, botComment := range botComments {
+			if err := minimizeComment(botComment.GetNodeID(), config, ctx); err != nil {
+				return err
+			}
+		}
+
+		return removeDuplicateLabel(issue, config, ctx)
+	}
+
+	if labelledHit(botComments) == candidates[0] {
+		return removeDuplicateLabel(issue, config, ctx)
+	}
+
+	return nil
+}
+
+// labelledHit returns the top hit the duplicate label was added for, it is the first hit of the duplicate comment.
+func labelledHit(botComments []*github.IssueComment) string {
+	for _, botComment := range botComments {
+		if hits := linkedHits(botComment.GetBody()); strings.HasPrefix(botComment.GetBody(), duplicatePrefix) && len(hits) > 0 {
+			return hits[0]
+		}
+	}
+
+	return ""
+}
+
+func listBotComments(issue int, config config.Config, ctx context.Context) ([]*github.IssueComment, error) {
+	var botComments []*github.IssueComment
+
+	options := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		comments, response, err := config.GithubClient.Issues.ListComments(ctx, owner, repository, issue, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments of issue GH-%d: %w", issue, err)
+		}
+
+		for _, comment := range comments {
+			if comment.GetUser().GetType() == "Bot" && isBotComment(comment.GetBody()) {
+				botComments = append(botComments, comment)
+			}
+		}
+
+		if response.NextPage == 0 {
+			return botComments, nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+func isBotComment(body string) bool {
+	return strings.HasPrefix(body, suggestionsPrefix) || strings.HasPrefix(body, duplicatePrefix)
+}
+
+// linkedHits returns the ids of the hits of a bot comment, top hit first.
+func linkedHits(body string) []string {
+	if match := hitIdsPattern.FindStringSubmatch(body); match != nil {
+		if match[1] == "" {
+			return nil
+		}
+
+		return strings.Split(match[1], ",")
+	}
+
+	var ids []string
+
+	for _, match := range hitLinkPattern.FindAllStringSubmatch(body, -1) {
+		if match[1] != "" {
+			ids = append(ids, "GH-"+match[1])
+		} else {
+			ids = append(ids, match[2])
+		}
+	}
+
+	return ids
+}
+
+// minimizeComment hides a comment as outdated, this is only possible with the GraphQL API.
+func minimizeComment(nodeId string, config config.Config, ctx context.Context) error {
+	query := `mutation($id: ID!) { minimizeComment(input: {subjectId: $id, classifier: OUTDATED}) { clientMutationId } }`
+
+	if err := graphql(query, map[string]any{"id": nodeId}, nil, config, ctx); err != nil {
+		return fmt.Errorf("failed to hide comment: %w", err)
+	}
+
+	return nil
+}
+
+func removeDuplicateLabel(issue int, config config.Config, ctx context.Context) error {
+	if config.GithubDuplicateLabel == "" {
+		return nil
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.GithubTimeout)
+	defer cancel()
+
+	response, err := config.GithubClient.Issues.RemoveLabelForIssue(ctx, owner, repository, issue, config.GithubDuplicateLabel)
+	if err != nil && (response == nil || response.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("failed to remove label from issue GH-%d: %w", issue, err)
+	}
+
+	return nil
+}
+
+func comment(issue int, body string, config config.Config, ctx context.Context) error {
+	ctx, cancel := config.WithTimeout(ctx, config.GithubTimeout)
+	defer cancel()
+
+	if _, _, err := config.GithubClient.Issues.CreateComment(ctx, owner, repository, issue, &github.IssueComment{Body: &body}); err != nil {
+		return fmt.Errorf("failed to comment on issue GH-%d: %w", issue, err)
+	}
+
+	return nil
+}
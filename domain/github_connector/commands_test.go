@@ -0,0 +1,249 @@
+package github_connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+func TestParseCommand(t *testing.T) {
+	cases := map[string]Command{
+		"/dedupe search":                             {Name: "search"},
+		"Thanks!\n/dedupe duplicate-of #123\n":       {Name: "duplicate-of", Issue: 123},
+		"/dedupe Duplicate-Of GH-5":                  {Name: "duplicate-of", Issue: 5},
+		"/dedupe not-duplicate":                      {Name: "not-duplicate"},
+		"/dedupe duplicate-of 123":                   {Name: "help"},
+		"/dedupe duplicate-of #99999999999999999999": {Name: "help"},
+		"/dedupe": {Name: "help"},
+		"/dedupe not-duplicate https://github.com/shopware/platform/issues/7": {Name: "not-duplicate", Issue: 7},
+	}
+
+	for body, expected := range cases {
+		command, found := ParseCommand(body)
+		if !found || command != expected {
+			t.Errorf("expected %q to be parsed as %+v, got %+v", body, expected, command)
+		}
+	}
+
+	if _, found := ParseCommand("Please run /dedupe search"); found {
+		t.Error("expected commands to start a line")
+	}
+}
+
+func TestLinkedHits(t *testing.T) {
+	body := PlanActions(1, append(hits(2.0), discussionHit("GHD-5", 1.9)), config.Config{}).Comment
+
+	if ids := strings.Join(linkedHits(body), ","); ids != "GH-1,GHD-5" {
+		t.Errorf("unexpected linked hits: %s", ids)
+	}
+
+	// Comments posted before the hit ids were added only have the links.
+	legacyBody := suggestionsPrefix + "- [Issue](https://github.com/shopware/platform/issues/1)\n- [Jira](https://issues.shopware.com/issues/NEXT-12)\n"
+
+	if ids := strings.Join(linkedHits(legacyBody), ","); ids != "GH-1,NEXT-12" {
+		t.Errorf("unexpected linked hits of a comment without hit ids: %s", ids)
+	}
+
+	if !isBotComment(body) {
+		t.Error("expected the suggestions to be recognized as bot comment")
+	}
+}
+
+type fakeGithub struct {
+	lock       sync.Mutex
+	requests   []string
+	judgments  []string
+	comments   []string
+	botComment string
+}
+
+func (f *fakeGithub) record(request string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.requests = append(f.requests, request)
+}
+
+func newFakeGithub(t *testing.T) (*fakeGithub, config.Config) {
+	t.Helper()
+
+	// GH-1 is the labelled top hit, GH-2 another suggestion.
+	fake := &fakeGithub{botComment: PlanActions(10, hits(2.0, 1.9), config.Config{GithubLabelMinScore: 2.0}).Comment}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		path, _ := url.PathUnescape(r.URL.Path)
+		fake.record(r.Method + " " + path)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(path, "/collaborators/maintainer/permission"):
+			fmt.Fprint(w, `{"permission": "write"}`)
+		case strings.HasSuffix(path, "/permission"):
+			fmt.Fprint(w, `{"permission": "read"}`)
+		case strings.HasPrefix(path, "/issues-judgments/_doc/"):
+			fake.lock.Lock()
+			fake.judgments = append(fake.judgments, string(body))
+			fake.lock.Unlock()
+			fmt.Fprint(w, `{"result": "created"}`)
+		case path == "/repos/shopware/platform/issues/10/comments" && r.Method == http.MethodGet:
+			fake.lock.Lock()
+			botComment, _ := json.Marshal(fake.botComment)
+			fake.lock.Unlock()
+			fmt.Fprintf(w, `[{"node_id": "IC_1", "body": %s, "user": {"type": "Bot"}}, {"node_id": "IC_2", "body": "Same here", "user": {"type": "User"}}]`, botComment)
+		case path == "/repos/shopware/platform/issues/10/comments":
+			var comment github.IssueComment
+			_ = json.Unmarshal(body, &comment)
+			fake.lock.Lock()
+			fake.comments = append(fake.comments, comment.GetBody())
+			fake.lock.Unlock()
+			fmt.Fprint(w, `{}`)
+		case path == "/graphql":
+			if !strings.Contains(string(body), `"IC_1"`) {
+				t.Errorf("expected only the bot comment to be hidden: %s", body)
+			}
+			fmt.Fprint(w, `{"data": {}}`)
+		case path == "/repos/shopware/platform/issues/404":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		case strings.Contains(path, "/labels/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Label does not exist"}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := opensearch.NewClient(opensearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	githubClient := github.NewClient(nil)
+	githubClient.BaseURL, _ = url.Parse(server.URL + "/")
+
+	return fake, config.Config{IndexName: "issues", OpensearchClient: client, GithubClient: githubClient, GithubDuplicateLabel: "possible-duplicate"}
+}
+
+func commentEvent(user string, body string) *github.IssueCommentEvent {
+	return &github.IssueCommentEvent{
+		Action:  github.String("created"),
+		Issue:   &github.Issue{Number: github.Int(10)},
+		Comment: &github.IssueComment{ID: github.Int64(99), Body: github.String(body), User: &github.User{Login: github.String(user), Type: github.String("User")}},
+	}
+}
+
+func TestDuplicateOfCommand(t *testing.T) {
+	fake, cfg := newFakeGithub(t)
+
+	if err := HandleGithubCommentEvent(commentEvent("maintainer", "/dedupe duplicate-of #123"), cfg, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := strings.Join(fake.requests, "\n")
+	if !strings.Contains(requests, "PATCH /repos/shopware/platform/issues/10") || !strings.Contains(requests, "POST /repos/shopware/platform/issues/comments/99/reactions") {
+		t.Errorf("expected the issue to be closed and the command to be acknowledged, requests:\n%s", requests)
+	}
+
+	if len(fake.judgments) != 1 || !strings.Contains(fake.judgments[0], `"candidateId":"GH-123","duplicate":true,"author":"maintainer","origin":"github"`) {
+		t.Errorf("unexpected judgments: %v", fake.judgments)
+	}
+
+	if len(fake.comments) != 1 || fake.comments[0] != "Duplicate of #123" {
+		t.Errorf("unexpected comments: %v", fake.comments)
+	}
+}
+
+func TestNotDuplicateCommand(t *testing.T) {
+	fake, cfg := newFakeGithub(t)
+
+	if err := HandleGithubCommentEvent(commentEvent("maintainer", "/dedupe not-duplicate"), cfg, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.judgments) != 2 || !strings.Contains(fake.judgments[0], `"candidateId":"GH-1","duplicate":false`) || !strings.Contains(fake.judgments[1], `"candidateId":"GH-2"`) {
+		t.Errorf("unexpected judgments: %v", fake.judgments)
+	}
+
+	if requests := strings.Join(fake.requests, "\n"); !strings.Contains(requests, "POST /graphql") || !strings.Contains(requests, "DELETE /repos/shopware/platform/issues/10/labels/possible-duplicate") {
+		t.Errorf("expected the bot comment to be hidden and the label to be removed, requests:\n%s", requests)
+	}
+}
+
+func TestNotDuplicateCommandKeepsLabelOfOtherTopHit(t *testing.T) {
+	for candidate, removesLabel := range map[string]bool{"#1": true, "#2": false} {
+		fake, cfg := newFakeGithub(t)
+
+		if err := HandleGithubCommentEvent(commentEvent("maintainer", "/dedupe not-duplicate "+candidate), cfg, context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		requests := strings.Join(fake.requests, "\n")
+		if strings.Contains(requests, "/labels/possible-duplicate") != removesLabel || strings.Contains(requests, "POST /graphql") {
+			t.Errorf("expected the label to be removed for %s only if it is the labelled top hit, requests:\n%s", candidate, requests)
+		}
+	}
+}
+
+func TestNotDuplicateCommandWithDiscussions(t *testing.T) {
+	fake, cfg := newFakeGithub(t)
+
+	// Only hits of other sources, the labelled top hit is a discussion.
+	fake.botComment = PlanActions(10, []search.IssueResult{discussionHit("GHD-5", 2.0), discussionHit("GHD-6", 1.9)}, config.Config{GithubLabelMinScore: 2.0}).Comment
+
+	if err := HandleGithubCommentEvent(commentEvent("maintainer", "/dedupe not-duplicate"), cfg, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.judgments) != 2 || !strings.Contains(fake.judgments[0], `"candidateId":"GHD-5","duplicate":false`) || !strings.Contains(fake.judgments[1], `"candidateId":"GHD-6"`) {
+		t.Errorf("unexpected judgments: %v", fake.judgments)
+	}
+
+	if requests := strings.Join(fake.requests, "\n"); !strings.Contains(requests, "POST /graphql") || !strings.Contains(requests, "DELETE /repos/shopware/platform/issues/10/labels/possible-duplicate") {
+		t.Errorf("expected the bot comment to be hidden and the label to be removed, requests:\n%s", requests)
+	}
+
+	if labelled := labelledHit([]*github.IssueComment{{Body: github.String(fake.botComment)}}); labelled != "GHD-5" {
+		t.Errorf("expected the discussion to be the labelled hit, got %s", labelled)
+	}
+}
+
+func TestDuplicateOfUnknownIssue(t *testing.T) {
+	fake, cfg := newFakeGithub(t)
+
+	if err := HandleGithubCommentEvent(commentEvent("maintainer", "/dedupe duplicate-of #404"), cfg, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := strings.Join(fake.requests, "\n")
+	if strings.Contains(requests, "PATCH") || len(fake.judgments) != 0 || len(fake.comments) != 0 {
+		t.Errorf("expected an unknown issue to be rejected, requests:\n%s", requests)
+	}
+}
+
+func TestCommandRequiresWritePermission(t *testing.T) {
+	fake, cfg := newFakeGithub(t)
+
+	if err := HandleGithubCommentEvent(commentEvent("visitor", "/dedupe duplicate-of #123"), cfg, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := strings.Join(fake.requests, "\n")
+	if strings.Contains(requests, "PATCH") || len(fake.judgments) != 0 || !strings.Contains(requests, "/comments/99/reactions") {
+		t.Errorf("expected only a reaction, requests:\n%s", requests)
+	}
+}
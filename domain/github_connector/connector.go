@@ -0,0 +1,141 @@
+package github_connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/samber/lo"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+)
+
+const (
+	owner      = "shopware"
+	repository = "platform"
+)
+
+// Connector indexes the issues and pull requests of shopware/platform.
+type Connector struct{}
+
+func (Connector) Name() string {
+	return "github"
+}
+
+// Fetch lists issues and pull requests by their last update, so a sync can continue where it stopped.
+func (Connector) Fetch(ctx context.Context, config config.Config, since time.Time, yield func(item json.RawMessage) error) error {
+	options := &github.IssueListByRepoOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+		State:       "all",
+		Sort:        "updated",
+		Direction:   "asc",
+		Since:       since,
+	}
+
+	for {
+		issues, response, err := config.GithubClient.Issues.ListByRepo(ctx, owner, repository, options)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			data, err := json.Marshal(issue)
+			if err != nil {
+				return err
+			}
+
+			if err := yield(data); err != nil {
+				return err
+			}
+		}
+
+		if response.NextPage == 0 {
+			return nil
+		}
+
+		options.Page = response.NextPage
+	}
+}
+
+func (Connector) Map(item json.RawMessage) (string, search.Document, error) {
+	var issue github.Issue
+	if err := json.Unmarshal(item, &issue); err != nil {
+		return "", search.Document{}, err
+	}
+
+	return fmt.Sprintf("GH-%d", issue.GetNumber()), issueDocument(&issue), nil
+}
+
+// webhookEvents are the event types counted in the metrics, all others are counted as metrics.OtherWebhookEvent.
+var webhookEvents = []string{"issues", "issue_comment", "pull_request", "discussion", "discussion_comment"}
+
+func (Connector) WebhookEvent(r *http.Request) string {
+	if event := github.WebHookType(r); lo.Contains(webhookEvents, event) {
+		return event
+	}
+
+	return metrics.OtherWebhookEvent
+}
+
+func (Connector) Webhook(config config.Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload []byte
+		var err error
+
+		if os.Getenv("CI") == "true" {
+			payload, err = io.ReadAll(r.Body)
+		} else {
+			payload, err = github.ValidatePayload(r, []byte(config.GithubWebhookSecret))
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		metrics.WebhookValidated(r.Context())
+
+		// go-github does not know discussion_comment, both discussion events only reindex the discussion.
+		if eventType := github.WebHookType(r); eventType == "discussion" || eventType == "discussion_comment" {
+			if err = HandleGithubDiscussionEvent(eventType, payload, config, r.Context()); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+
+			return
+		}
+
+		event, err := github.ParseWebHook(github.WebHookType(r), payload)
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch event := event.(type) {
+		case *github.IssuesEvent:
+			if err = HandleGithubIssueEvent(event, config, r.Context()); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		case *github.IssueCommentEvent:
+			if err = HandleGithubCommentEvent(event, config, r.Context()); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		case *github.PullRequestEvent:
+			if err = HandleGithubPREvent(event, config, r.Context()); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package github_connector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookEvent(t *testing.T) {
+	for header, expected := range map[string]string{"issues": "issues", "discussion_comment": "discussion_comment", "star": "other", "": "other"} {
+		request := httptest.NewRequest(http.MethodPost, "/webhook/github", nil)
+		request.Header.Set("X-GitHub-Event", header)
+
+		if event := (Connector{}).WebhookEvent(request); event != expected {
+			t.Errorf("expected event %s for header %q, got %s", expected, header, event)
+		}
+	}
+}
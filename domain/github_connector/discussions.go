@@ -0,0 +1,325 @@
+package github_connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+)
+
+const discussionsSource = "github-discussions"
+
+const discussionAnswerPrefix = "We found the following resolved issues and answered discussions which may help with your question: \n"
+
+// ActionDiscussionComment answers a new discussion with similar resolved issues and answered discussions.
+const ActionDiscussionComment Action = "discussion-comment"
+
+// discussionFields are the fields of Discussion, the sync and the webhooks index the same fields.
+const discussionFields = `id number title body url closed stateReason isAnswered createdAt updatedAt upvoteCount
+        author { login url }
+        category { name }
+        labels(first: 20) { nodes { name } }`
+
+const discussionsQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: 100, after: $cursor, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        ` + discussionFields + `
+      }
+    }
+  }
+}`
+
+const discussionQuery = `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    discussion(number: $number) {
+      ` + discussionFields + `
+    }
+  }
+}`
+
+// DiscussionsConnector indexes the discussions of shopware/platform. Their webhooks arrive at /webhook/github
+// together with the issue events, so it is no WebhookConnector.
+type DiscussionsConnector struct{}
+
+// Discussion is a discussion as returned by the GraphQL API.
+type Discussion struct {
+	Id          string    `json:"id"`
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	Url         string    `json:"url"`
+	Closed      bool      `json:"closed"`
+	StateReason string    `json:"stateReason"`
+	IsAnswered  bool      `json:"isAnswered"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	UpvoteCount int       `json:"upvoteCount"`
+	Author      struct {
+		Login string `json:"login"`
+		Url   string `json:"url"`
+	} `json:"author"`
+	Category struct {
+		Name string `json:"name"`
+	} `json:"category"`
+	Labels struct {
+		Nodes []discussionLabel `json:"nodes"`
+	} `json:"labels"`
+}
+
+type discussionLabel struct {
+	Name string `json:"name"`
+}
+
+func (DiscussionsConnector) Name() string {
+	return discussionsSource
+}
+
+// Fetch pages through the discussions by their last update, newest first, and stops at the first one older than since.
+func (DiscussionsConnector) Fetch(ctx context.Context, config config.Config, since time.Time, yield func(item json.RawMessage) error) error {
+	var cursor *string
+
+	for {
+		var data struct {
+			Repository struct {
+				Discussions struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []json.RawMessage `json:"nodes"`
+				} `json:"discussions"`
+			} `json:"repository"`
+		}
+
+		variables := map[string]any{"owner": owner, "name": repository, "cursor": cursor}
+		if err := graphql(discussionsQuery, variables, &data, config, ctx); err != nil {
+			return fmt.Errorf("failed to list discussions: %w", err)
+		}
+
+		for _, node := range data.Repository.Discussions.Nodes {
+			var discussion Discussion
+			if err := json.Unmarshal(node, &discussion); err != nil {
+				return err
+			}
+
+			if !since.IsZero() && discussion.UpdatedAt.Before(since) {
+				return nil
+			}
+
+			if err := yield(node); err != nil {
+				return err
+			}
+		}
+
+		page := data.Repository.Discussions.PageInfo
+		if !page.HasNextPage {
+			return nil
+		}
+
+		cursor = &page.EndCursor
+	}
+}
+
+func (DiscussionsConnector) Map(item json.RawMessage) (string, search.Document, error) {
+	var discussion Discussion
+	if err := json.Unmarshal(item, &discussion); err != nil {
+		return "", search.Document{}, err
+	}
+
+	return discussionId(discussion.Number), discussionDocument(discussion), nil
+}
+
+func discussionId(number int) string {
+	return fmt.Sprintf("GHD-%d", number)
+}
+
+// discussionDocument counts answered and resolved discussions as closed, so they are found like resolved issues.
+// Discussions closed for another reason, e.g. as outdated or duplicate, are dismissed and never suggested as answer.
+func discussionDocument(discussion Discussion) search.Document {
+	status := "open"
+	switch {
+	case discussion.IsAnswered || (discussion.Closed && discussion.StateReason == "RESOLVED"):
+		status = "closed"
+	case discussion.Closed:
+		status = "dismissed"
+	}
+
+	var labels []string
+	if discussion.Category.Name != "" {
+		labels = append(labels, discussion.Category.Name)
+	}
+
+	for _, label := range discussion.Labels.Nodes {
+		labels = append(labels, label.Name)
+	}
+
+	return search.Document{
+		Title:        discussion.Title,
+		Description:  search.CleanupString(discussion.Body),
+		Status:       status,
+		Type:         "Discussion",
+		Link:         discussion.Url,
+		ExternalLink: discussion.Url,
+		FixVersion:   []string{"n/a"},
+		Public:       true,
+		Source:       discussionsSource,
+		AuthorName:   discussion.Author.Login,
+		AuthorLink:   discussion.Author.Url,
+		DateCreated:  discussion.CreatedAt.Unix(),
+		Labels:       labels,
+		Reactions:    discussion.UpvoteCount,
+	}
+}
+
+// discussionEvent is a discussion or discussion_comment webhook, go-github only knows the former.
+type discussionEvent struct {
+	Action     string             `json:"action"`
+	Discussion *github.Discussion `json:"discussion"`
+}
+
+// getDiscussion fetches a discussion like the sync does, the webhooks lack the upvotes of it.
+func getDiscussion(number int, config config.Config, ctx context.Context) (Discussion, error) {
+	var data struct {
+		Repository struct {
+			Discussion *Discussion `json:"discussion"`
+		} `json:"repository"`
+	}
+
+	variables := map[string]any{"owner": owner, "name": repository, "number": number}
+	if err := graphql(discussionQuery, variables, &data, config, ctx); err != nil {
+		return Discussion{}, fmt.Errorf("failed to fetch discussion GHD-%d: %w", number, err)
+	}
+
+	if data.Repository.Discussion == nil {
+		return Discussion{}, fmt.Errorf("discussion GHD-%d not found", number)
+	}
+
+	return *data.Repository.Discussion, nil
+}
+
+// HandleGithubDiscussionEvent reindexes the discussion of a discussion or discussion_comment webhook and answers
+// new discussions if GITHUB_DISCUSSION_ANSWER is enabled. Deleted and transferred discussions are removed. The
+// discussion is fetched again, so it is indexed like by the sync.
+func HandleGithubDiscussionEvent(eventType string, payload []byte, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	var event discussionEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return err
+	}
+
+	if event.Discussion == nil {
+		return fmt.Errorf("%s webhook without discussion", eventType)
+	}
+
+	number := event.Discussion.GetNumber()
+
+	if eventType == "discussion" && (event.Action == "deleted" || event.Action == "transferred") {
+		if err := search.DeleteDocument(discussionId(number), config, ctx); err != nil {
+			logger.Errorf("Error while deleting GitHub discussion: %s", err)
+			return err
+		}
+
+		logger.Debugf("Deleted GitHub discussion: %d", number)
+
+		return nil
+	}
+
+	discussion, err := getDiscussion(number, config, ctx)
+	if err != nil {
+		logger.Errorf("Error while fetching GitHub discussion: %s", err)
+		return err
+	}
+
+	if err := search.IndexDocument(discussionId(discussion.Number), discussionDocument(discussion), config, ctx); err != nil {
+		logger.Errorf("Error while indexing GitHub discussion: %s", err)
+	} else {
+		logger.Debugf("Indexed GitHub discussion: %d", discussion.Number)
+	}
+
+	if eventType != "discussion" || event.Action != "created" || !config.GithubDiscussionAnswer {
+		return nil
+	}
+
+	return answerDiscussion(discussion, config, ctx)
+}
+
+// answerDiscussion comments the resolved issues and answered discussions which are similar to a new discussion.
+func answerDiscussion(discussion Discussion, config config.Config, ctx context.Context) (err error) {
+	logger := logging.FromContext(ctx).With("discussion", discussion.Number, "dryRun", config.GithubActionsDryRun)
+
+	result, err := search.Search(
+		discussion.Title,
+		discussion.Body,
+		search.SearchFilter{
+			ExcludedDocumentId: discussionId(discussion.Number),
+			OnlyPublic:         true,
+			MinScore:           config.GithubCommentMinScore,
+			Status:             "closed",
+			Sources:            []string{"github", discussionsSource},
+		},
+		config,
+		ctx,
+	)
+	if err != nil {
+		return err
+	}
+
+	body := discussionAnswer(result.Hits.Hits)
+
+	action := ActionDiscussionComment
+	if body == "" {
+		action = ActionNone
+	}
+
+	defer func() {
+		metrics.GithubActions.WithLabelValues(string(action), strconv.FormatBool(config.GithubActionsDryRun), metrics.Outcome(err)).Inc()
+	}()
+
+	if action == ActionNone {
+		logger.Debugf("No answer for discussion GHD-%d", discussion.Number)
+		return nil
+	}
+
+	if config.GithubActionsDryRun {
+		logger.Infof("Dry run, would answer discussion GHD-%d:\n%s", discussion.Number, body)
+		return nil
+	}
+
+	query := `mutation($id: ID!, $body: String!) { addDiscussionComment(input: {discussionId: $id, body: $body}) { clientMutationId } }`
+
+	if err := graphql(query, map[string]any{"id": discussion.Id, "body": body}, nil, config, ctx); err != nil {
+		return fmt.Errorf("failed to answer discussion GHD-%d: %w", discussion.Number, err)
+	}
+
+	logger.Infof("Answered discussion GHD-%d", discussion.Number)
+
+	return nil
+}
+
+// discussionAnswer is empty if none of the hits can be linked.
+func discussionAnswer(hits []search.IssueResult) string {
+	var output strings.Builder
+
+	for _, hit := range hits {
+		if link := hitLink(hit); link != "" {
+			output.WriteString(fmt.Sprintf("- [%s](%s)\n", hit.Source.Title, link))
+		}
+	}
+
+	if output.Len() == 0 {
+		return ""
+	}
+
+	return discussionAnswerPrefix + output.String()
+}
@@ -0,0 +1,183 @@
+package github_connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+func TestDiscussionMap(t *testing.T) {
+	item := `{"id": "D_1", "number": 7, "title": "How to extend the cart?", "body": "Question", "url": "https://github.com/shopware/platform/discussions/7",
+		"closed": false, "isAnswered": true, "createdAt": "2023-04-01T10:00:00Z", "upvoteCount": 3,
+		"author": {"login": "jane", "url": "https://github.com/jane"}, "category": {"name": "Q&A"}, "labels": {"nodes": [{"name": "checkout"}]}}`
+
+	id, document, err := DiscussionsConnector{}.Map(json.RawMessage(item))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "GHD-7" || document.Type != "Discussion" || document.Source != "github-discussions" {
+		t.Errorf("unexpected id %s or document %+v", id, document)
+	}
+
+	if document.Status != "closed" || document.Reactions != 3 || strings.Join(document.Labels, ",") != "Q&A,checkout" {
+		t.Errorf("expected an answered discussion to be closed with upvotes and labels: %+v", document)
+	}
+}
+
+func TestDiscussionDocumentStatus(t *testing.T) {
+	cases := []struct {
+		discussion Discussion
+		status     string
+	}{
+		{Discussion{}, "open"},
+		{Discussion{IsAnswered: true}, "closed"},
+		{Discussion{Closed: true, StateReason: "RESOLVED"}, "closed"},
+		{Discussion{Closed: true, StateReason: "OUTDATED"}, "dismissed"},
+		{Discussion{Closed: true, StateReason: "DUPLICATE"}, "dismissed"},
+	}
+
+	for _, c := range cases {
+		if document := discussionDocument(c.discussion); document.Status != c.status {
+			t.Errorf("expected status %s for %+v, got %s", c.status, c.discussion, document.Status)
+		}
+	}
+}
+
+func TestDiscussionFetchStopsAtSince(t *testing.T) {
+	var cursors []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Variables struct {
+				Cursor *string `json:"cursor"`
+			} `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if request.Variables.Cursor == nil {
+			cursors = append(cursors, "")
+			fmt.Fprint(w, `{"data": {"repository": {"discussions": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+				"nodes": [{"number": 3, "updatedAt": "2023-05-03T00:00:00Z"}, {"number": 2, "updatedAt": "2023-05-02T00:00:00Z"}]}}}}`)
+			return
+		}
+
+		cursors = append(cursors, *request.Variables.Cursor)
+		fmt.Fprint(w, `{"data": {"repository": {"discussions": {"pageInfo": {"hasNextPage": true, "endCursor": "c2"},
+			"nodes": [{"number": 1, "updatedAt": "2023-04-01T00:00:00Z"}]}}}}`)
+	}))
+	defer server.Close()
+
+	githubClient := github.NewClient(nil)
+	githubClient.BaseURL, _ = url.Parse(server.URL + "/")
+
+	var ids []string
+	err := DiscussionsConnector{}.Fetch(context.Background(), config.Config{GithubClient: githubClient}, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), func(item json.RawMessage) error {
+		id, _, err := DiscussionsConnector{}.Map(item)
+		ids = append(ids, id)
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(ids, ",") != "GHD-3,GHD-2" || strings.Join(cursors, ",") != ",c1" {
+		t.Errorf("expected the fetch to stop at the first discussion older than since, got %v with cursors %v", ids, cursors)
+	}
+}
+
+func TestDiscussionWebhookAnswersNewDiscussions(t *testing.T) {
+	var lock sync.Mutex
+	var answers, indexed, deleted []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/graphql" && strings.Contains(string(body), "addDiscussionComment"):
+			answers = append(answers, string(body))
+			fmt.Fprint(w, `{"data": {}}`)
+		case r.URL.Path == "/graphql":
+			// The webhook payload has no upvotes, the discussion is fetched again.
+			fmt.Fprint(w, `{"data": {"repository": {"discussion": {"id": "D_9", "number": 9, "title": "Cart", "body": "How?", "upvoteCount": 4, "category": {"name": "Q&A"}}}}}`)
+		case strings.HasSuffix(r.URL.Path, "/_search"):
+			if !strings.Contains(string(body), `"github-discussions"`) || !strings.Contains(string(body), `"closed"`) {
+				t.Errorf("expected only resolved issues and answered discussions to be searched: %s", body)
+			}
+			fmt.Fprint(w, `{"hits": {"hits": [
+				{"_id": "GH-5", "_score": 3, "_source": {"title": "Cart issue", "source": "github"}},
+				{"_id": "GHD-2", "_score": 2, "_source": {"title": "Cart question", "source": "github-discussions", "link": "https://github.com/shopware/platform/discussions/2"}}]}}`)
+		case strings.HasPrefix(r.URL.Path, "/issues/_doc/") && r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			fmt.Fprint(w, `{"result": "deleted"}`)
+		case strings.HasPrefix(r.URL.Path, "/issues/_doc/"):
+			indexed = append(indexed, r.URL.Path)
+			if !strings.Contains(string(body), `"reactions":4`) {
+				t.Errorf("expected the upvotes of the discussion to be indexed: %s", body)
+			}
+			fmt.Fprint(w, `{"result": "created"}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer server.Close()
+
+	client, err := opensearch.NewClient(opensearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	githubClient := github.NewClient(nil)
+	githubClient.BaseURL, _ = url.Parse(server.URL + "/")
+
+	cfg := config.Config{IndexName: "issues", OpensearchClient: client, GithubClient: githubClient, GithubDiscussionAnswer: true}
+
+	payload := `{"action": "created", "discussion": {"node_id": "D_9", "number": 9, "title": "Cart", "body": "How?", "state": "open", "category": {"name": "Q&A"}}}`
+
+	if err := HandleGithubDiscussionEvent("discussion", []byte(payload), cfg, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := HandleGithubDiscussionEvent("discussion_comment", []byte(payload), cfg, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, action := range []string{"deleted", "transferred"} {
+		removed := strings.Replace(payload, `"created"`, `"`+action+`"`, 1)
+		if err := HandleGithubDiscussionEvent("discussion", []byte(removed), cfg, context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(indexed) != 2 || indexed[0] != "/issues/_doc/GHD-9" {
+		t.Errorf("expected both events to reindex the discussion: %v", indexed)
+	}
+
+	if len(deleted) != 2 || deleted[0] != "/issues/_doc/GHD-9" {
+		t.Errorf("expected deleted and transferred discussions to be removed: %v", deleted)
+	}
+
+	if len(answers) != 1 || !strings.Contains(answers[0], `"D_9"`) || !strings.Contains(answers[0], "issues/5") || !strings.Contains(answers[0], "discussions/2") {
+		t.Errorf("expected one answer linking the issue and the discussion: %v", answers)
+	}
+}
@@ -0,0 +1,46 @@
+package github_connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// graphql runs a query against the GitHub GraphQL API and decodes its data into result, discussions and
+// hiding comments are not available in the REST API.
+func graphql(query string, variables map[string]any, result any, config config.Config, ctx context.Context) error {
+	ctx, cancel := config.WithTimeout(ctx, config.GithubTimeout)
+	defer cancel()
+
+	req, err := config.GithubClient.NewRequest(http.MethodPost, "graphql", map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if _, err := config.GithubClient.Do(ctx, req, &response); err != nil {
+		return fmt.Errorf("GitHub GraphQL request failed: %w", err)
+	}
+
+	if len(response.Errors) > 0 {
+		return fmt.Errorf("GitHub GraphQL request failed: %s", response.Errors[0].Message)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(response.Data, result)
+}
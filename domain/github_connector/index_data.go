@@ -1,15 +1,42 @@
 package github_connector
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/go-github/v50/github"
 	"github.com/shopwarelabs/jira-issue-bot/domain/search"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
-	"go.uber.org/zap"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
 )
 
-func IndexSingleGitHubIssue(issue *github.Issue, config config.Config, logger *zap.SugaredLogger) error {
+func IndexSingleGitHubIssue(issue *github.Issue, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	err := search.IndexDocument(fmt.Sprintf("GH-%d", issue.GetNumber()), issueDocument(issue), config, ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Indexed GitHub issue: %d", issue.GetNumber())
+
+	return nil
+}
+
+func IndexSingleGitHubPr(pr *github.PullRequest, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	err := search.IndexDocument(fmt.Sprintf("GH-%d", pr.GetNumber()), pullRequestDocument(pr), config, ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Indexed GitHub pull request: %d", pr.GetNumber())
+
+	return nil
+}
+
+func issueDocument(issue *github.Issue) search.Document {
 	var issueType string
 	if issue.IsPullRequest() {
 		issueType = "Pull Request"
@@ -22,7 +49,7 @@ func IndexSingleGitHubIssue(issue *github.Issue, config config.Config, logger *z
 		labels = append(labels, label.GetName())
 	}
 
-	document := search.Document{
+	return search.Document{
 		Title:        issue.GetTitle(),
 		Description:  search.CleanupString(issue.GetBody()),
 		Status:       issue.GetState(),
@@ -36,25 +63,17 @@ func IndexSingleGitHubIssue(issue *github.Issue, config config.Config, logger *z
 		AuthorLink:   issue.GetUser().GetHTMLURL(),
 		DateCreated:  issue.CreatedAt.Unix(),
 		Labels:       labels,
+		Reactions:    issue.GetReactions().GetTotalCount(),
 	}
-
-	err := search.IndexDocument(fmt.Sprintf("GH-%d", issue.GetNumber()), document, config)
-	if err != nil {
-		return err
-	}
-
-	logger.Debugf("Indexed GitHub issue: %d", issue.GetNumber())
-
-	return nil
 }
 
-func IndexSingleGitHubPr(pr *github.PullRequest, config config.Config, logger *zap.SugaredLogger) error {
+func pullRequestDocument(pr *github.PullRequest) search.Document {
 	var labels []string
 	for _, label := range pr.Labels {
 		labels = append(labels, label.GetName())
 	}
 
-	document := search.Document{
+	return search.Document{
 		Title:        pr.GetTitle(),
 		Description:  search.CleanupString(pr.GetBody()),
 		Status:       pr.GetState(),
@@ -69,13 +88,4 @@ func IndexSingleGitHubPr(pr *github.PullRequest, config config.Config, logger *z
 		DateCreated:  pr.CreatedAt.Unix(),
 		Labels:       labels,
 	}
-
-	err := search.IndexDocument(fmt.Sprintf("GH-%d", pr.GetNumber()), document, config)
-	if err != nil {
-		return err
-	}
-
-	logger.Debugf("Indexed GitHub pull request: %d", pr.GetNumber())
-
-	return nil
 }
@@ -0,0 +1,152 @@
+package judgment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// Judgment is a human decision whether a search hit is a duplicate of a document or query.
+type Judgment struct {
+	// DocumentId is the indexed document the search was run for, empty for free text searches.
+	DocumentId  string  `json:"documentId,omitempty"`
+	Query       string  `json:"query,omitempty"`
+	CandidateId string  `json:"candidateId"`
+	Duplicate   bool    `json:"duplicate"`
+	Score       float64 `json:"score,omitempty"`
+	Author      string  `json:"author,omitempty"`
+	Origin      string  `json:"origin"`
+	Date        int64   `json:"date"`
+}
+
+func (j Judgment) Validate() error {
+	if j.CandidateId == "" {
+		return fmt.Errorf("candidateId is required")
+	}
+
+	if j.DocumentId == "" && j.Query == "" {
+		return fmt.Errorf("documentId or query is required")
+	}
+
+	if j.DocumentId == j.CandidateId {
+		return fmt.Errorf("a document can not be a duplicate of itself")
+	}
+
+	return nil
+}
+
+// id makes sure that judging the same pair again overwrites the previous judgment.
+func (j Judgment) id() string {
+	if j.DocumentId != "" {
+		return j.DocumentId + ":" + j.CandidateId
+	}
+
+	return ""
+}
+
+// Record stores the judgment, judgments for the same document and candidate replace each other.
+func Record(judgment Judgment, config config.Config, ctx context.Context) error {
+	if err := judgment.Validate(); err != nil {
+		return err
+	}
+
+	if judgment.Date == 0 {
+		judgment.Date = time.Now().Unix()
+	}
+
+	jsonString, _ := json.Marshal(judgment)
+
+	req := opensearchapi.IndexRequest{
+		Index:      Index(config),
+		DocumentID: judgment.id(),
+		Body:       bytes.NewReader(jsonString),
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.IndexTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		return fmt.Errorf("failed to record judgment: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		body, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("failed to record judgment: %s", body)
+	}
+
+	return nil
+}
+
+// ForDocument returns all judgments which were recorded for the given document.
+func ForDocument(documentId string, config config.Config, ctx context.Context) ([]Judgment, error) {
+	documentIdJson, _ := json.Marshal(documentId)
+
+	req := opensearchapi.SearchRequest{
+		Index: []string{Index(config)},
+		Body: strings.NewReader(fmt.Sprintf(`{
+			"size": 1000,
+			"query": { "term": { "documentId.keyword": %s }}
+		}`, documentIdJson)),
+		IgnoreUnavailable: opensearchapi.BoolPtr(true),
+	}
+
+	return search(req, config, ctx)
+}
+
+// All returns every recorded judgment.
+func All(config config.Config, ctx context.Context) ([]Judgment, error) {
+	req := opensearchapi.SearchRequest{
+		Index:             []string{Index(config)},
+		Body:              strings.NewReader(`{ "size": 10000, "query": { "match_all": {} }}`),
+		IgnoreUnavailable: opensearchapi.BoolPtr(true),
+	}
+
+	return search(req, config, ctx)
+}
+
+func search(req opensearchapi.SearchRequest, config config.Config, ctx context.Context) ([]Judgment, error) {
+	ctx, cancel := config.WithTimeout(ctx, config.SearchTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch judgments: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source Judgment `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode opensearch response: %w", err)
+	}
+
+	judgments := make([]Judgment, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		judgments = append(judgments, hit.Source)
+	}
+
+	return judgments, nil
+}
+
+func Index(config config.Config) string {
+	return config.IndexName + "-judgments"
+}
@@ -0,0 +1,26 @@
+package search
+
+import "regexp"
+
+// Every script_score clause of the search query carries a "field" param, which shows up in the
+// description of its explanation, e.g. `params={field=title}`.
+var scriptFieldParam = regexp.MustCompile(`params=\{field=(\w+)}`)
+
+// scoreBreakdown returns how much each field contributed to the score of a hit.
+func scoreBreakdown(explanation Explanation) map[string]float64 {
+	breakdown := map[string]float64{}
+	collectScores(explanation, breakdown)
+
+	return breakdown
+}
+
+func collectScores(explanation Explanation, breakdown map[string]float64) {
+	if match := scriptFieldParam.FindStringSubmatch(explanation.Description); match != nil {
+		breakdown[match[1]] += explanation.Value
+		return
+	}
+
+	for _, detail := range explanation.Details {
+		collectScores(detail, breakdown)
+	}
+}
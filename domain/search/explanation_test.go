@@ -0,0 +1,30 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScoreBreakdown(t *testing.T) {
+	explanation := Explanation{
+		Value:       3.3,
+		Description: "sum of:",
+		Details: []Explanation{
+			{
+				Value:       1.8,
+				Description: `script score function, computed with script:"Script{type=inline, lang='painless', idOrCode='_score * 1.8', options={}, params={field=title}}"`,
+				Details:     []Explanation{{Value: 1, Description: "within top 100 docs"}},
+			},
+			{
+				Value:       1.5,
+				Description: `script score function, computed with script:"Script{type=inline, lang='painless', idOrCode='_score * 1.5', options={}, params={field=description}}"`,
+			},
+		},
+	}
+
+	expected := map[string]float64{"title": 1.8, "description": 1.5}
+
+	if actual := scoreBreakdown(explanation); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected breakdown %v, got %v", expected, actual)
+	}
+}
@@ -2,18 +2,60 @@ package search
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"text/template"
 )
 
+const (
+	// DefaultMinScore is the minimum score a hit needs when the filter does not specify one.
+	DefaultMinScore = 1.8
+
+	DefaultTitleWeight       = 1.8
+	DefaultDescriptionWeight = 1.5
+	// DefaultNeighbours is the number of nearest neighbours each embedding query returns.
+	DefaultNeighbours = 100
+)
+
+// Ranking controls how the similarities of title and description are combined, zero values use the defaults.
+// With a LexicalWeight a keyword match on title and description is added to the score, which makes it a hybrid search.
+type Ranking struct {
+	TitleWeight       float64 `json:"titleWeight,omitempty" yaml:"titleWeight"`
+	DescriptionWeight float64 `json:"descriptionWeight,omitempty" yaml:"descriptionWeight"`
+	Neighbours        int     `json:"neighbours,omitempty" yaml:"neighbours"`
+	LexicalWeight     float64 `json:"lexicalWeight,omitempty" yaml:"lexicalWeight"`
+}
+
+// IsDefault reports whether the ranking scores like the bot, unset weights use the defaults.
+func (r Ranking) IsDefault() bool {
+	return r.withDefaults() == Ranking{}.withDefaults()
+}
+
+func (r Ranking) withDefaults() Ranking {
+	if r.TitleWeight == 0 {
+		r.TitleWeight = DefaultTitleWeight
+	}
+
+	if r.DescriptionWeight == 0 {
+		r.DescriptionWeight = DefaultDescriptionWeight
+	}
+
+	if r.Neighbours == 0 {
+		r.Neighbours = DefaultNeighbours
+	}
+
+	return r
+}
+
 func parseFilter(title string, description string, modelId string, filter SearchFilter) bytes.Buffer {
 	must := make([]string, 0)
 	mustNot := make([]string, 0)
 	should := make([]string, 0)
 
 	if filter.ExcludedDocumentId != "" {
-		mustNot = append(mustNot, fmt.Sprintf(`{ "ids": { "values": ["%s"] }}`, filter.ExcludedDocumentId))
+		excludedDocumentId, _ := json.Marshal(filter.ExcludedDocumentId)
+		mustNot = append(mustNot, fmt.Sprintf(`{ "ids": { "values": [%s] }}`, excludedDocumentId))
 	}
 
 	if filter.OnlyPublic {
@@ -21,37 +63,71 @@ func parseFilter(title string, description string, modelId string, filter Search
 	}
 
 	if filter.Source != "" {
-		must = append(must, fmt.Sprintf(`{ "match": { "source": "%s" }}`, filter.Source))
+		source, _ := json.Marshal(filter.Source)
+		// The analyzed source field would also match "github-discussions" for "github".
+		must = append(must, fmt.Sprintf(`{ "term": { "source.keyword": %s }}`, source))
+	}
+
+	if filter.Status != "" {
+		status, _ := json.Marshal(filter.Status)
+		must = append(must, fmt.Sprintf(`{ "term": { "status.keyword": %s }}`, status))
 	}
 
+	if len(filter.Labels) > 0 {
+		labels, _ := json.Marshal(filter.Labels)
+		must = append(must, fmt.Sprintf(`{ "terms": { "labels.keyword": %s }}`, labels))
+	}
+
+	if len(filter.Sources) > 0 {
+		sources, _ := json.Marshal(filter.Sources)
+		must = append(must, fmt.Sprintf(`{ "terms": { "source.keyword": %s }}`, sources))
+	}
+
+	ranking := filter.Ranking.withDefaults()
 	should = append(should, fmt.Sprintf(`
 {
   "script_score": {
     "query": {
       "neural": {
-        "title_embedding": { "model_id": "%s", "k": 100, "query_text": %s }
+        "title_embedding": { "model_id": "%s", "k": %d, "query_text": %s }
       }
     },
-    "script": { "source": "_score * 1.8"}
+    "script": { "source": "_score * %s", "params": { "field": "title" }}
   }
 }
-`, modelId, title))
+`, modelId, ranking.Neighbours, title, jsonFloat(ranking.TitleWeight)))
 
 	should = append(should, fmt.Sprintf(`
 {
   "script_score": {
     "query": {
       "neural": {
-        "description_embedding": { "model_id": "%s", "k": 100, "query_text": %s }
+        "description_embedding": { "model_id": "%s", "k": %d, "query_text": %s }
       }
     },
-    "script": { "source": "_score * 1.5" }
+    "script": { "source": "_score * %s", "params": { "field": "description" }}
   }
-}`, modelId, description))
+}`, modelId, ranking.Neighbours, description, jsonFloat(ranking.DescriptionWeight)))
+
+	if ranking.LexicalWeight > 0 {
+		should = append(should, fmt.Sprintf(`
+{
+  "multi_match": { "query": %s, "fields": ["title^2", "description"], "boost": %s }
+}`, lexicalQuery(title, description), jsonFloat(ranking.LexicalWeight)))
+	}
 
 	query := `
 {
-    "min_score": 1.8,
+    {{if .Size}}
+    "size": {{.Size}},
+    {{end}}
+    {{if .From}}
+    "from": {{.From}},
+    {{end}}
+    {{if .Explain}}
+    "explain": true,
+    {{end}}
+    "min_score": {{.MinScore}},
     "query": {
         "bool": {
             {{if .MustNot}}
@@ -78,7 +154,16 @@ func parseFilter(title string, description string, modelId string, filter Search
 
 	var search bytes.Buffer
 
+	minScore := filter.MinScore
+	if minScore == 0 {
+		minScore = DefaultMinScore
+	}
+
 	searchData := searchData{
+		Size:          filter.Size,
+		From:          filter.From,
+		Explain:       filter.Explain,
+		MinScore:      minScore,
 		Must:          len(must) > 0,
 		MustNot:       len(mustNot) > 0,
 		Should:        len(should) > 0,
@@ -96,6 +181,10 @@ func parseFilter(title string, description string, modelId string, filter Search
 }
 
 type searchData struct {
+	Size          int
+	From          int
+	Explain       bool
+	MinScore      float64
 	Must          bool
 	MustNot       bool
 	Should        bool
@@ -103,3 +192,20 @@ type searchData struct {
 	MustNotString string
 	ShouldString  string
 }
+
+// lexicalQuery joins the JSON encoded title and description to one JSON string.
+func lexicalQuery(title string, description string) string {
+	var decodedTitle, decodedDescription string
+	_ = json.Unmarshal([]byte(title), &decodedTitle)
+	_ = json.Unmarshal([]byte(description), &decodedDescription)
+
+	query, _ := json.Marshal(strings.TrimSpace(decodedTitle + " " + decodedDescription))
+
+	return string(query)
+}
+
+func jsonFloat(value float64) string {
+	encoded, _ := json.Marshal(value)
+
+	return string(encoded)
+}
@@ -21,7 +21,7 @@ func TestParseFilterWithEmptyFilter(t *testing.T) {
 								"title_embedding": { "model_id": "modelId", "k": 100, "query_text": title }
 							}
 						},
-						"script": { "source": "_score * 1.8"}
+						"script": { "source": "_score * 1.8", "params": { "field": "title" }}
 					}
 				},
 				{
@@ -31,7 +31,7 @@ func TestParseFilterWithEmptyFilter(t *testing.T) {
 								"description_embedding": { "model_id": "modelId", "k": 100, "query_text": description }
 							}
 						},
-						"script": { "source": "_score * 1.5" }
+						"script": { "source": "_score * 1.5", "params": { "field": "description" }}
 					}
 				}
             ]
@@ -59,7 +59,7 @@ func TestParseFilterWithAllFilterOptions(t *testing.T) {
             ],
             "must": [
             	{ "match": { "public": true }},
-				{ "match": { "source": "source" }}
+				{ "term": { "source.keyword": "source" }}
             ],
             "should": [
 				{
@@ -69,7 +69,7 @@ func TestParseFilterWithAllFilterOptions(t *testing.T) {
 								"title_embedding": { "model_id": "modelId", "k": 100, "query_text": title }
 							}
 						},
-						"script": { "source": "_score * 1.8"}
+						"script": { "source": "_score * 1.8", "params": { "field": "title" }}
 					}
 				},
 				{
@@ -79,7 +79,7 @@ func TestParseFilterWithAllFilterOptions(t *testing.T) {
 								"description_embedding": { "model_id": "modelId", "k": 100, "query_text": description }
 							}
 						},
-						"script": { "source": "_score * 1.5" }
+						"script": { "source": "_score * 1.5", "params": { "field": "description" }}
 					}
 				}
             ]
@@ -111,7 +111,7 @@ func TestParseFilterWithExcludedDocumentId(t *testing.T) {
 								"title_embedding": { "model_id": "modelId", "k": 100, "query_text": title }
 							}
 						},
-						"script": { "source": "_score * 1.8"}
+						"script": { "source": "_score * 1.8", "params": { "field": "title" }}
 					}
 				},
 				{
@@ -121,7 +121,7 @@ func TestParseFilterWithExcludedDocumentId(t *testing.T) {
 								"description_embedding": { "model_id": "modelId", "k": 100, "query_text": description }
 							}
 						},
-						"script": { "source": "_score * 1.5" }
+						"script": { "source": "_score * 1.5", "params": { "field": "description" }}
 					}
 				}
             ]
@@ -143,7 +143,7 @@ func TestParseFilterWithSource(t *testing.T) {
     "query": {
         "bool": {
             "must": [
-				{ "match": { "source": "source" }}
+				{ "term": { "source.keyword": "source" }}
             ],
             "should": [
 				{
@@ -153,7 +153,7 @@ func TestParseFilterWithSource(t *testing.T) {
 								"title_embedding": { "model_id": "modelId", "k": 100, "query_text": title }
 							}
 						},
-						"script": { "source": "_score * 1.8"}
+						"script": { "source": "_score * 1.8", "params": { "field": "title" }}
 					}
 				},
 				{
@@ -163,7 +163,7 @@ func TestParseFilterWithSource(t *testing.T) {
 								"description_embedding": { "model_id": "modelId", "k": 100, "query_text": description }
 							}
 						},
-						"script": { "source": "_score * 1.5" }
+						"script": { "source": "_score * 1.5", "params": { "field": "description" }}
 					}
 				}
             ]
@@ -195,7 +195,7 @@ func TestParseFilterWithOnlyPublicFilter(t *testing.T) {
 								"title_embedding": { "model_id": "modelId", "k": 100, "query_text": title }
 							}
 						},
-						"script": { "source": "_score * 1.8"}
+						"script": { "source": "_score * 1.8", "params": { "field": "title" }}
 					}
 				},
 				{
@@ -205,7 +205,7 @@ func TestParseFilterWithOnlyPublicFilter(t *testing.T) {
 								"description_embedding": { "model_id": "modelId", "k": 100, "query_text": description }
 							}
 						},
-						"script": { "source": "_score * 1.5" }
+						"script": { "source": "_score * 1.5", "params": { "field": "description" }}
 					}
 				}
             ]
@@ -216,6 +216,95 @@ func TestParseFilterWithOnlyPublicFilter(t *testing.T) {
 	assertQuery(t, expected, parsedQueryString)
 }
 
+func TestParseFilterWithSourcesMinScoreAndPagination(t *testing.T) {
+	parsedQuery := parseFilter("title", "description", "modelId", SearchFilter{
+		Sources:  []string{"github", "jira"},
+		MinScore: 2.5,
+		Size:     5,
+		From:     10,
+	})
+	parsedQueryString := parsedQuery.String()
+
+	expected := `{
+	"size": 5,
+	"from": 10,
+	"min_score": 2.5,
+    "query": {
+        "bool": {
+            "must": [
+				{ "terms": { "source.keyword": ["github","jira"] }}
+            ],
+            "should": [
+				{
+					"script_score": {
+						"query": {
+							"neural": {
+								"title_embedding": { "model_id": "modelId", "k": 100, "query_text": title }
+							}
+						},
+						"script": { "source": "_score * 1.8", "params": { "field": "title" }}
+					}
+				},
+				{
+					"script_score": {
+						"query": {
+							"neural": {
+								"description_embedding": { "model_id": "modelId", "k": 100, "query_text": description }
+							}
+						},
+						"script": { "source": "_score * 1.5", "params": { "field": "description" }}
+					}
+				}
+            ]
+        }
+    }
+}`
+
+	assertQuery(t, expected, parsedQueryString)
+}
+
+func TestParseFilterWithRanking(t *testing.T) {
+	parsedQuery := parseFilter(`"title"`, `"description"`, "modelId", SearchFilter{
+		Ranking: Ranking{TitleWeight: 2, DescriptionWeight: 0.5, Neighbours: 50, LexicalWeight: 0.1},
+	})
+	parsedQueryString := parsedQuery.String()
+
+	expected := `{
+	"min_score": 1.8,
+    "query": {
+        "bool": {
+            "should": [
+				{
+					"script_score": {
+						"query": {
+							"neural": {
+								"title_embedding": { "model_id": "modelId", "k": 50, "query_text": "title" }
+							}
+						},
+						"script": { "source": "_score * 2", "params": { "field": "title" }}
+					}
+				},
+				{
+					"script_score": {
+						"query": {
+							"neural": {
+								"description_embedding": { "model_id": "modelId", "k": 50, "query_text": "description" }
+							}
+						},
+						"script": { "source": "_score * 0.5", "params": { "field": "description" }}
+					}
+				},
+				{
+					"multi_match": { "query": "title description", "fields": ["title^2", "description"], "boost": 0.1 }
+				}
+            ]
+        }
+    }
+}`
+
+	assertQuery(t, expected, parsedQueryString)
+}
+
 func assertQuery(t *testing.T, expected string, actual string) {
 	t.Helper()
 
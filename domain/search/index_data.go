@@ -6,25 +6,50 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func IndexDocument(id string, document Document, config config.Config) error {
+func IndexDocument(id string, document Document, config config.Config, ctx context.Context) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "search.IndexDocument", trace.WithAttributes(
+		attribute.String("document.id", id),
+		attribute.String("document.source", document.Source),
+	))
+
+	defer func() {
+		metrics.IndexedDocuments.WithLabelValues(document.Source, metrics.Outcome(err)).Inc()
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+	}()
+
 	if document.Description == "" {
 		document.Description = document.Title
 	}
 
 	jsonString, _ := json.Marshal(document)
 
+	ctx, cancel := config.WithTimeout(ctx, config.IndexTimeout)
+	defer cancel()
+
 	req := opensearchapi.IndexRequest{
 		Index:      config.IndexName,
 		DocumentID: id,
 		Body:       bytes.NewReader(jsonString),
 	}
 
-	resp, err := req.Do(context.Background(), config.OpensearchClient)
+	resp, err := req.Do(ctx, config.OpensearchClient)
 	if err != nil {
 		return fmt.Errorf("failed to insert document: %w", err)
 	}
@@ -57,4 +82,33 @@ type Document struct {
 	AuthorLink   string   `json:"authorLink"`
 	DateCreated  int64    `json:"dateCreated"`
 	Labels       []string `json:"labels"`
+	// Reactions counts the reactions of GitHub issues, the votes of Stack Overflow questions and GitHub Discussions
+	// and the likes of forum topics.
+	Reactions int `json:"reactions"`
+}
+
+// DeleteDocument removes a document from the index, a document which is not indexed is no error.
+func DeleteDocument(id string, config config.Config, ctx context.Context) error {
+	ctx, cancel := config.WithTimeout(ctx, config.IndexTimeout)
+	defer cancel()
+
+	req := opensearchapi.DeleteRequest{
+		Index:      config.IndexName,
+		DocumentID: id,
+	}
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.IsError() && resp.StatusCode != http.StatusNotFound {
+		body, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("failed to delete document: %s", body)
+	}
+
+	return nil
 }
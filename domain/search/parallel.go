@@ -0,0 +1,28 @@
+package search
+
+import "sync"
+
+// SearchConcurrency limits the parallel searches of batch runs, every search creates an embedding in OpenSearch.
+const SearchConcurrency = 10
+
+// Parallel calls run for every index below n, at most SearchConcurrency at a time, and waits for all calls.
+func Parallel(n int, run func(i int)) {
+	var wg sync.WaitGroup
+	guard := make(chan struct{}, SearchConcurrency)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		guard <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-guard
+				wg.Done()
+			}()
+
+			run(i)
+		}(i)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,39 @@
+package search
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParallel(t *testing.T) {
+	var lock sync.Mutex
+	running, maxRunning := 0, 0
+	called := make([]bool, 3*SearchConcurrency)
+
+	Parallel(len(called), func(i int) {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		called[i] = true
+		lock.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+	})
+
+	for i, ok := range called {
+		if !ok {
+			t.Errorf("expected index %d to be called", i)
+		}
+	}
+
+	if maxRunning > SearchConcurrency {
+		t.Errorf("expected at most %d parallel calls, got %d", SearchConcurrency, maxRunning)
+	}
+}
@@ -3,15 +3,43 @@ package search
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func Search(title string, description string, filter SearchFilter, config config.Config) (*SearchResponse, error) {
+var ErrDocumentNotFound = errors.New("issue not found")
+
+func Search(title string, description string, filter SearchFilter, config config.Config, ctx context.Context) (result *SearchResponse, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "search.Search")
+
+	ctx, cancel := config.WithTimeout(ctx, config.SearchTimeout)
+	defer cancel()
+
+	defer func(start time.Time) {
+		metrics.SearchDuration.WithLabelValues(metrics.Outcome(err)).Observe(time.Since(start).Seconds())
+
+		if err == nil {
+			metrics.SearchHits.Observe(float64(len(result.Hits.Hits)))
+			span.SetAttributes(attribute.Int("search.hits", len(result.Hits.Hits)))
+		} else {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+	}(time.Now())
+
 	encodedTitle, _ := json.Marshal(title)
 	encodedDescription, _ := json.Marshal(CleanupString(description))
 
@@ -22,30 +50,52 @@ func Search(title string, description string, filter SearchFilter, config config
 		Body:  strings.NewReader(search.String()),
 	}
 
-	resp, err := req.Do(context.Background(), config.OpensearchClient)
+	resp, err := req.Do(ctx, config.OpensearchClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute search: %w", err)
 	}
 
-	var result SearchResponse
+	result = &SearchResponse{}
 
 	body, _ := io.ReadAll(resp.Body)
 	defer resp.Body.Close()
 
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := json.Unmarshal(body, result); err != nil {
 		return nil, fmt.Errorf("failed to decode opensearch response: %w", err)
 	}
 
-	return &result, nil
+	for i, hit := range result.Hits.Hits {
+		if hit.Explanation != nil {
+			result.Hits.Hits[i].Breakdown = scoreBreakdown(*hit.Explanation)
+		}
+	}
+
+	return result, nil
+}
+
+func SearchId(id string, filter SearchFilter, config config.Config, ctx context.Context) (*SearchResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "search.SearchId", trace.WithAttributes(attribute.String("document.id", id)))
+	defer span.End()
+
+	document, err := GetDocument(id, config, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return Search(document.Title, document.Description, filter, config, ctx)
 }
 
-func SearchId(id string, filter SearchFilter, config config.Config) (*SearchResponse, error) {
+// GetDocument fetches an indexed document, it returns ErrDocumentNotFound if the id is not indexed.
+func GetDocument(id string, config config.Config, ctx context.Context) (*Document, error) {
+	docCtx, cancel := config.WithTimeout(ctx, config.SearchTimeout)
+	defer cancel()
+
 	docReq := opensearchapi.GetRequest{
 		Index:      config.IndexName,
 		DocumentID: id,
 	}
 
-	docResp, err := docReq.Do(context.Background(), config.OpensearchClient)
+	docResp, err := docReq.Do(docCtx, config.OpensearchClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute document fetch: %w", err)
 	}
@@ -60,16 +110,25 @@ func SearchId(id string, filter SearchFilter, config config.Config) (*SearchResp
 	}
 
 	if !issue.Found {
-		return nil, fmt.Errorf("issue not found")
+		return nil, ErrDocumentNotFound
 	}
 
-	return Search(issue.Source.Title, issue.Source.Description, filter, config)
+	return &issue.Source, nil
 }
 
 type SearchFilter struct {
 	ExcludedDocumentId string
 	Source             string
+	Sources            []string
 	OnlyPublic         bool
+	MinScore           float64
+	Size               int
+	From               int
+	Status             string
+	Labels             []string
+	// Explain adds a per field score breakdown to every hit, see IssueResult.Breakdown.
+	Explain bool
+	Ranking Ranking
 }
 
 type SearchResponse struct {
@@ -92,10 +151,18 @@ type SearchResponse struct {
 }
 
 type IssueResult struct {
-	Index  string   `json:"_index"`
-	ID     string   `json:"_id"`
-	Score  float64  `json:"_score"`
-	Source Document `json:"_source"`
+	Index       string             `json:"_index"`
+	ID          string             `json:"_id"`
+	Score       float64            `json:"_score"`
+	Source      Document           `json:"_source"`
+	Explanation *Explanation       `json:"_explanation,omitempty"`
+	Breakdown   map[string]float64 `json:"breakdown,omitempty"`
+}
+
+type Explanation struct {
+	Value       float64       `json:"value"`
+	Description string        `json:"description"`
+	Details     []Explanation `json:"details"`
 }
 
 type IssueSearchResponse struct {
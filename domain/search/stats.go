@@ -0,0 +1,63 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// SourceStats returns the number of indexed documents per source.
+func SourceStats(config config.Config, ctx context.Context) ([]SourceStat, error) {
+	req := opensearchapi.SearchRequest{
+		Index: []string{config.IndexName},
+		Body: strings.NewReader(`{
+			"size": 0,
+			"aggs": {
+				"sources": { "terms": { "field": "source.keyword", "size": 50 }}
+			}
+		}`),
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.SearchTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch source stats: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	var result struct {
+		Aggregations struct {
+			Sources struct {
+				Buckets []struct {
+					Key      string `json:"key"`
+					DocCount int    `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"sources"`
+		} `json:"aggregations"`
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode opensearch response: %w", err)
+	}
+
+	stats := make([]SourceStat, 0, len(result.Aggregations.Sources.Buckets))
+	for _, bucket := range result.Aggregations.Sources.Buckets {
+		stats = append(stats, SourceStat{Source: bucket.Key, Documents: bucket.DocCount})
+	}
+
+	return stats, nil
+}
+
+type SourceStat struct {
+	Source    string `json:"source"`
+	Documents int    `json:"documents"`
+}
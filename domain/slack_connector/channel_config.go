@@ -0,0 +1,295 @@
+package slack_connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// channelConfigTtl limits how long a channel config is cached, so changes made on other instances apply soon.
+const channelConfigTtl = time.Minute
+
+// channelConfigs caches the configs of monitored channels, every message in every channel of the bot needs one.
+var channelConfigs = newChannelConfigCache()
+
+// ChannelConfig controls how the bot searches and answers in a single Slack channel.
+//
+// Channel configs are resolved in the following order: the config stored in OpenSearch with
+// `/issues config`, the entry for the channel in SLACK_CHANNEL_CONFIG, the "*" entry in
+// SLACK_CHANNEL_CONFIG and finally the zero value, which searches everything.
+type ChannelConfig struct {
+	Sources    []string `json:"sources,omitempty"`
+	OnlyPublic bool     `json:"onlyPublic"`
+	MinScore   float64  `json:"minScore,omitempty"`
+	MaxResults int      `json:"maxResults,omitempty"`
+	Language   string   `json:"language,omitempty"`
+
+	Monitor         bool    `json:"monitor"`
+	MonitorMinScore float64 `json:"monitorMinScore,omitempty"`
+}
+
+// SearchFilter builds the search filter which is used for all searches in the channel.
+func (c ChannelConfig) SearchFilter() search.SearchFilter {
+	return search.SearchFilter{
+		Sources:    c.Sources,
+		OnlyPublic: c.OnlyPublic,
+		MinScore:   c.MinScore,
+		Size:       c.MaxResults,
+	}
+}
+
+func (c ChannelConfig) String() string {
+	sources := "all"
+	if len(c.Sources) > 0 {
+		sources = strings.Join(c.Sources, ",")
+	}
+
+	minScore := "default"
+	if c.MinScore > 0 {
+		minScore = strconv.FormatFloat(c.MinScore, 'f', -1, 64)
+	}
+
+	maxResults := "default"
+	if c.MaxResults > 0 {
+		maxResults = strconv.Itoa(c.MaxResults)
+	}
+
+	monitorMinScore := "default"
+	if c.MonitorMinScore > 0 {
+		monitorMinScore = strconv.FormatFloat(c.MonitorMinScore, 'f', -1, 64)
+	}
+
+	return fmt.Sprintf(
+		"sources=%s only_public=%t min_score=%s max_results=%s language=%s monitor=%t monitor_min_score=%s",
+		sources, c.OnlyPublic, minScore, maxResults, languageOrDefault(c.Language), c.Monitor, monitorMinScore,
+	)
+}
+
+func GetChannelConfig(channelId string, config config.Config, ctx context.Context) (ChannelConfig, error) {
+	req := opensearchapi.GetRequest{
+		Index:      channelConfigIndex(config),
+		DocumentID: channelId,
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.SearchTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		return ChannelConfig{}, fmt.Errorf("failed to fetch channel config: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	// Channels without a stored config, or without the index so far, use the one from SLACK_CHANNEL_CONFIG.
+	if resp.StatusCode == http.StatusNotFound {
+		return staticChannelConfig(channelId, config)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.IsError() {
+		return ChannelConfig{}, fmt.Errorf("failed to fetch channel config: %s", body)
+	}
+
+	var stored struct {
+		Source ChannelConfig `json:"_source"`
+	}
+
+	if err := json.Unmarshal(body, &stored); err != nil {
+		return ChannelConfig{}, fmt.Errorf("failed to decode channel config: %w", err)
+	}
+
+	return stored.Source, nil
+}
+
+func SaveChannelConfig(channelId string, channelConfig ChannelConfig, config config.Config, ctx context.Context) error {
+	jsonString, _ := json.Marshal(channelConfig)
+
+	req := opensearchapi.IndexRequest{
+		Index:      channelConfigIndex(config),
+		DocumentID: channelId,
+		Body:       bytes.NewReader(jsonString),
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.IndexTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		return fmt.Errorf("failed to save channel config: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		body, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("failed to save channel config: %s", body)
+	}
+
+	channelConfigs.Invalidate(channelId)
+
+	return nil
+}
+
+func DeleteChannelConfig(channelId string, config config.Config, ctx context.Context) error {
+	req := opensearchapi.DeleteRequest{
+		Index:      channelConfigIndex(config),
+		DocumentID: channelId,
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.IndexTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		return fmt.Errorf("failed to delete channel config: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.IsError() && resp.StatusCode != http.StatusNotFound {
+		body, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("failed to delete channel config: %s", body)
+	}
+
+	channelConfigs.Invalidate(channelId)
+
+	return nil
+}
+
+type cachedChannelConfig struct {
+	channelConfig ChannelConfig
+	expires       time.Time
+}
+
+// channelConfigCache keeps channel configs for channelConfigTtl, failed lookups are not cached.
+type channelConfigCache struct {
+	lock    sync.Mutex
+	entries map[string]cachedChannelConfig
+	now     func() time.Time
+}
+
+func newChannelConfigCache() *channelConfigCache {
+	return &channelConfigCache{entries: map[string]cachedChannelConfig{}, now: time.Now}
+}
+
+func (c *channelConfigCache) Get(channelId string, config config.Config, ctx context.Context) (ChannelConfig, error) {
+	c.lock.Lock()
+	entry, ok := c.entries[channelId]
+	c.lock.Unlock()
+
+	if ok && c.now().Before(entry.expires) {
+		return entry.channelConfig, nil
+	}
+
+	channelConfig, err := GetChannelConfig(channelId, config, ctx)
+	if err != nil {
+		return ChannelConfig{}, err
+	}
+
+	c.lock.Lock()
+	c.entries[channelId] = cachedChannelConfig{channelConfig: channelConfig, expires: c.now().Add(channelConfigTtl)}
+	c.lock.Unlock()
+
+	return channelConfig, nil
+}
+
+// Invalidate drops the cached config of the channel, e.g. after it was changed with `/issues config`.
+func (c *channelConfigCache) Invalidate(channelId string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.entries, channelId)
+}
+
+func staticChannelConfig(channelId string, config config.Config) (ChannelConfig, error) {
+	if config.SlackChannelConfig == "" {
+		return ChannelConfig{}, nil
+	}
+
+	var channelConfigs map[string]ChannelConfig
+	if err := json.Unmarshal([]byte(config.SlackChannelConfig), &channelConfigs); err != nil {
+		return ChannelConfig{}, fmt.Errorf("failed to decode SLACK_CHANNEL_CONFIG: %w", err)
+	}
+
+	if channelConfig, ok := channelConfigs[channelId]; ok {
+		return channelConfig, nil
+	}
+
+	return channelConfigs["*"], nil
+}
+
+func channelConfigIndex(config config.Config) string {
+	return config.IndexName + "-slack-channels"
+}
+
+// parseChannelConfigArgs applies "key=value" arguments of the `/issues config` command to the given config.
+func parseChannelConfigArgs(channelConfig ChannelConfig, args []string) (ChannelConfig, error) {
+	for _, arg := range args {
+		key, value, found := strings.Cut(arg, "=")
+		if !found {
+			return channelConfig, fmt.Errorf("invalid argument \"%s\", expected key=value", arg)
+		}
+
+		switch key {
+		case "sources":
+			channelConfig.Sources = nil
+			if value != "" && value != "all" {
+				channelConfig.Sources = strings.Split(value, ",")
+			}
+		case "only_public":
+			onlyPublic, err := strconv.ParseBool(value)
+			if err != nil {
+				return channelConfig, fmt.Errorf("invalid value \"%s\" for only_public", value)
+			}
+			channelConfig.OnlyPublic = onlyPublic
+		case "min_score":
+			minScore, err := strconv.ParseFloat(value, 64)
+			if err != nil || minScore < 0 {
+				return channelConfig, fmt.Errorf("invalid value \"%s\" for min_score", value)
+			}
+			channelConfig.MinScore = minScore
+		case "max_results":
+			maxResults, err := strconv.Atoi(value)
+			if err != nil || maxResults < 0 {
+				return channelConfig, fmt.Errorf("invalid value \"%s\" for max_results", value)
+			}
+			channelConfig.MaxResults = maxResults
+		case "monitor":
+			monitor, err := strconv.ParseBool(value)
+			if err != nil {
+				return channelConfig, fmt.Errorf("invalid value \"%s\" for monitor", value)
+			}
+			channelConfig.Monitor = monitor
+		case "monitor_min_score":
+			monitorMinScore, err := strconv.ParseFloat(value, 64)
+			if err != nil || monitorMinScore < 0 {
+				return channelConfig, fmt.Errorf("invalid value \"%s\" for monitor_min_score", value)
+			}
+			channelConfig.MonitorMinScore = monitorMinScore
+		case "language":
+			if _, ok := translations[value]; !ok {
+				return channelConfig, fmt.Errorf("unsupported language \"%s\"", value)
+			}
+			channelConfig.Language = value
+		default:
+			return channelConfig, fmt.Errorf("unknown setting \"%s\"", key)
+		}
+	}
+
+	return channelConfig, nil
+}
@@ -0,0 +1,184 @@
+package slack_connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+func TestParseChannelConfigArgs(t *testing.T) {
+	actual, err := parseChannelConfigArgs(ChannelConfig{Language: "en"}, []string{
+		"sources=github,stack-overflow",
+		"only_public=true",
+		"min_score=2.2",
+		"max_results=5",
+		"language=de",
+		"monitor=true",
+		"monitor_min_score=3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ChannelConfig{
+		Sources:    []string{"github", "stack-overflow"},
+		OnlyPublic: true,
+		MinScore:   2.2,
+		MaxResults: 5,
+		Language:   "de",
+
+		Monitor:         true,
+		MonitorMinScore: 3,
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected channel config %+v, got %+v", expected, actual)
+	}
+}
+
+func TestParseChannelConfigArgsResetsSources(t *testing.T) {
+	actual, err := parseChannelConfigArgs(ChannelConfig{Sources: []string{"jira"}}, []string{"sources=all"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.Sources != nil {
+		t.Errorf("expected sources to be reset, got %v", actual.Sources)
+	}
+}
+
+func TestParseChannelConfigArgsWithInvalidArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{"sources"},
+		{"only_public=maybe"},
+		{"min_score=-1"},
+		{"max_results=many"},
+		{"language=tlh"},
+		{"monitor=sometimes"},
+		{"unknown=1"},
+	} {
+		if _, err := parseChannelConfigArgs(ChannelConfig{}, args); err == nil {
+			t.Errorf("expected an error for arguments %v", args)
+		}
+	}
+}
+
+func TestStaticChannelConfigFallsBackToWildcard(t *testing.T) {
+	cfg := config.Config{SlackChannelConfig: `{"C1": {"onlyPublic": true}, "*": {"maxResults": 3}}`}
+
+	channelConfig, err := staticChannelConfig("C1", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !channelConfig.OnlyPublic {
+		t.Errorf("expected channel C1 to only search public documents")
+	}
+
+	channelConfig, err = staticChannelConfig("C2", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if channelConfig.MaxResults != 3 {
+		t.Errorf("expected channel C2 to use the wildcard config, got %+v", channelConfig)
+	}
+}
+
+func TestGetChannelConfigOnlyFallsBackWhenNotFound(t *testing.T) {
+	status := http.StatusNotFound
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, `{"found": false}`)
+	}))
+	defer server.Close()
+
+	client, err := opensearch.NewClient(opensearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{IndexName: "issues", OpensearchClient: client, SlackChannelConfig: `{"*": {"maxResults": 3}}`}
+
+	channelConfig, err := GetChannelConfig("C1", cfg, context.Background())
+	if err != nil || channelConfig.MaxResults != 3 {
+		t.Errorf("expected the static config for a channel without stored config, got %+v, %v", channelConfig, err)
+	}
+
+	status = http.StatusForbidden
+
+	if _, err := GetChannelConfig("C1", cfg, context.Background()); err == nil {
+		t.Error("expected an error when OpenSearch rejects the request")
+	}
+}
+
+func TestChannelConfigCache(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodPut {
+			fmt.Fprint(w, `{"result": "updated"}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"found": true, "_source": {"monitor": true}}`)
+	}))
+	defer server.Close()
+
+	client, err := opensearch.NewClient(opensearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{IndexName: "issues", OpensearchClient: client}
+
+	now := time.Now()
+	cache := newChannelConfigCache()
+	cache.now = func() time.Time { return now }
+
+	get := func() {
+		t.Helper()
+
+		if channelConfig, err := cache.Get("C1", cfg, context.Background()); err != nil || !channelConfig.Monitor {
+			t.Fatalf("expected the stored config, got %+v, %v", channelConfig, err)
+		}
+	}
+
+	get()
+	get()
+
+	if requests != 1 {
+		t.Errorf("expected the second lookup to be cached, got %d requests", requests)
+	}
+
+	now = now.Add(channelConfigTtl)
+	get()
+
+	if requests != 2 {
+		t.Errorf("expected an expired config to be fetched again, got %d requests", requests)
+	}
+
+	channelConfigs = cache
+	defer func() { channelConfigs = newChannelConfigCache() }()
+
+	if err := SaveChannelConfig("C1", ChannelConfig{Monitor: true}, cfg, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	get()
+
+	if requests != 4 {
+		t.Errorf("expected a saved config to be fetched again, got %d requests", requests)
+	}
+}
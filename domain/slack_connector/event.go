@@ -0,0 +1,27 @@
+package slack_connector
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/slack-go/slack/slackevents"
+)
+
+var ErrUnsupportedEvent = errors.New("unsupported slack event")
+
+// OnEvent dispatches an Events API callback event to its handler, independent of the transport it was received on.
+func OnEvent(event slackevents.EventsAPIInnerEvent, config config.Config, ctx context.Context) error {
+	switch data := event.Data.(type) {
+	case *slackevents.AppMentionEvent:
+		return OnMention(data, config, ctx)
+	case *slackevents.MessageEvent:
+		return OnChannelMessage(data, config, ctx)
+	case *slackevents.ReactionAddedEvent:
+		return OnReactionAdded(data, config, ctx)
+	case *slackevents.AppHomeOpenedEvent:
+		return OnAppHomeOpened(data, config, ctx)
+	default:
+		return ErrUnsupportedEvent
+	}
+}
@@ -1,40 +1,52 @@
 package slack_connector
 
 import (
+	"context"
 	"regexp"
 
 	"github.com/shopwarelabs/jira-issue-bot/domain/search"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
-	"go.uber.org/zap"
 )
 
-func OnMention(event *slackevents.AppMentionEvent, config config.Config, logger *zap.SugaredLogger) error {
-	api := slack.New(config.SlackBotToken)
+func OnMention(event *slackevents.AppMentionEvent, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	api := newSlackClient(config)
 
 	logger.Debugf("Try to find recommendations for message: %s", event.TimeStamp)
 
 	regex := regexp.MustCompile(`<@U\d+[A-Z]+>`)
 	searchTerm := regex.ReplaceAllString(event.Text, "")
 
+	channelConfig, err := GetChannelConfig(event.Channel, config, ctx)
+	if err != nil {
+		return err
+	}
+
 	result, err := search.Search(
 		searchTerm,
 		searchTerm,
-		search.SearchFilter{},
+		channelConfig.SearchFilter(),
 		config,
+		ctx,
 	)
 
 	if err != nil {
 		return err
 	}
 
+	recordQuery(event.User, event.Channel, searchTerm, result, config, ctx)
+
 	if len(result.Hits.Hits) == 0 {
 		logger.Debugf("Did not find any recommendations for message: %s", event.TimeStamp)
 
-		_, _, err := api.PostMessage(
+		_, _, err := api.PostMessageContext(
+			ctx,
 			event.Channel,
-			slack.MsgOptionBlocks(noResultsSectionBlock()),
+			slack.MsgOptionBlocks(noResultsSectionBlock(channelConfig.Language)),
 			slack.MsgOptionAsUser(true),
 			slack.MsgOptionTS(event.TimeStamp),
 		)
@@ -48,10 +60,11 @@ func OnMention(event *slackevents.AppMentionEvent, config config.Config, logger
 
 	logger.Debugf("Found %d recommendations for message %s", len(result.Hits.Hits), event.TimeStamp)
 
-	headerSection := headerSectionBlock()
+	headerSection := headerSectionBlock(channelConfig.Language)
 	listSection := resultListSectionBlock(result)
 
-	_, _, err = api.PostMessage(
+	_, _, err = api.PostMessageContext(
+		ctx,
 		event.Channel,
 		slack.MsgOptionBlocks(headerSection, listSection),
 		slack.MsgOptionAsUser(true),
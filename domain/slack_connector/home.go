@@ -0,0 +1,274 @@
+package slack_connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/shopwarelabs/jira-issue-bot/domain/dry_run"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	// homeClusters is the number of duplicate clusters on the App Home, they are refreshed in the background every
+	// homeClustersTTL as every new issue of the week is searched to build them.
+	homeClusters    = 5
+	homeClustersTTL = 15 * time.Minute
+)
+
+var recentClusters = newClusterCache(loadRecentClusters)
+
+const (
+	OpenSearchModalActionId = "open_search_modal"
+	SearchModalCallbackId   = "search_modal"
+
+	searchModalQueryBlockId  = "query"
+	searchModalQueryActionId = "query"
+)
+
+// OnAppHomeOpened publishes the App Home dashboard for the user who opened it.
+func OnAppHomeOpened(event *slackevents.AppHomeOpenedEvent, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	if event.Tab != "home" {
+		return nil
+	}
+
+	logger.Debugf("Publishing App Home for user %s", event.User)
+
+	_, err := newSlackClient(config).PublishViewContext(ctx, event.User, homeView(event.User, config, ctx), "")
+
+	return err
+}
+
+func homeView(userId string, config config.Config, ctx context.Context) slack.HomeTabViewRequest {
+	logger := logging.FromContext(ctx)
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", "🔎 Issue search", false, false)),
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", "Search GitHub, Jira and Stack Overflow for existing issues.", false, false),
+			nil,
+			slack.NewAccessory(slack.NewButtonBlockElement(
+				OpenSearchModalActionId,
+				"",
+				slack.NewTextBlockObject("plain_text", "Search", false, false),
+			).WithStyle(slack.StylePrimary)),
+		),
+		slack.NewDividerBlock(),
+		slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", "Your recent queries", false, false)),
+	}
+
+	queries, err := RecentQueries(userId, 5, config, ctx)
+	if err != nil {
+		logger.Errorf("Error while fetching recent queries: %s", err)
+	}
+
+	output := strings.Builder{}
+	for _, query := range queries {
+		output.WriteString(fmt.Sprintf("• %s _(%d results, <!date^%d^{date_short_pretty}|%s>)_\n", query.Query, len(query.HitIds), query.Date, time.Unix(query.Date, 0).Format(time.RFC822)))
+	}
+	blocks = append(blocks, homeListBlock(output.String(), "You didn't search for anything yet"))
+
+	blocks = append(blocks,
+		slack.NewDividerBlock(),
+		slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", "Top duplicate clusters of the last week", false, false)),
+	)
+
+	clusters, loaded := recentClusters.Get(config, ctx)
+
+	output.Reset()
+	for _, cluster := range lo.Slice(clusters, 0, homeClusters) {
+		output.WriteString(fmt.Sprintf("• <%s|%s> _(%d similar issues, best score %.2f)_\n", cluster.Canonical.Link, cluster.Canonical.Title, len(cluster.Documents)-1, cluster.Score))
+	}
+	if loaded {
+		blocks = append(blocks, homeListBlock(output.String(), "No duplicates were found in the last week"))
+	} else {
+		blocks = append(blocks, homeListBlock("", "The duplicate clusters are being computed, check back in a few minutes"))
+	}
+
+	blocks = append(blocks,
+		slack.NewDividerBlock(),
+		slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", "Indexed documents", false, false)),
+	)
+
+	stats, err := search.SourceStats(config, ctx)
+	if err != nil {
+		logger.Errorf("Error while fetching index stats: %s", err)
+	}
+
+	output.Reset()
+	for _, stat := range stats {
+		output.WriteString(fmt.Sprintf("• *%s*: %d\n", stat.Source, stat.Documents))
+	}
+	blocks = append(blocks, homeListBlock(output.String(), "The index is empty"))
+
+	return slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+}
+
+// loadRecentClusters groups the open GitHub issues of the last week like the clusters command does.
+func loadRecentClusters(config config.Config, ctx context.Context) ([]dry_run.Cluster, error) {
+	options := dry_run.DefaultOptions()
+	options.Since = time.Now().AddDate(0, 0, -7).Unix()
+
+	report, err := dry_run.RunClusters(options, dry_run.CanonicalOldest, config, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.Clusters, nil
+}
+
+// clusterCache keeps the last duplicate clusters for the App Home, which must not wait for them.
+type clusterCache struct {
+	lock       sync.Mutex
+	clusters   []dry_run.Cluster
+	loaded     bool
+	refreshed  time.Time
+	refreshing bool
+	now        func() time.Time
+	load       func(config config.Config, ctx context.Context) ([]dry_run.Cluster, error)
+}
+
+func newClusterCache(load func(config config.Config, ctx context.Context) ([]dry_run.Cluster, error)) *clusterCache {
+	return &clusterCache{now: time.Now, load: load}
+}
+
+// Get returns the last clusters and whether they were loaded at all. Clusters older than homeClustersTTL are
+// refreshed in the background, a failed refresh keeps the previous clusters and is only retried after
+// homeClustersTTL as well.
+func (c *clusterCache) Get(config config.Config, ctx context.Context) ([]dry_run.Cluster, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.refreshing && c.now().Sub(c.refreshed) >= homeClustersTTL {
+		c.refreshing = true
+
+		go c.refresh(config, ctx)
+	}
+
+	return c.clusters, c.loaded
+}
+
+func (c *clusterCache) refresh(config config.Config, ctx context.Context) {
+	logger := logging.FromContext(ctx)
+
+	clusters, err := c.load(config, ctx)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.refreshing, c.refreshed = false, c.now()
+
+	if err != nil {
+		logger.Errorf("Error while fetching duplicate clusters: %s", err)
+		return
+	}
+
+	c.clusters, c.loaded = clusters, true
+}
+
+func homeListBlock(text string, fallback string) *slack.SectionBlock {
+	if text == "" {
+		text = "_" + fallback + "_"
+	}
+
+	return slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil)
+}
+
+func searchModal(query string, blocks ...slack.Block) slack.ModalViewRequest {
+	element := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject("plain_text", "e.g. Paging in custom field sets does not work", false, false),
+		searchModalQueryActionId,
+	)
+	element.InitialValue = query
+
+	input := slack.NewInputBlock(
+		searchModalQueryBlockId,
+		slack.NewTextBlockObject("plain_text", "What are you looking for?", false, false),
+		nil,
+		element,
+	)
+
+	return slack.ModalViewRequest{
+		Type:       slack.VTModal,
+		CallbackID: SearchModalCallbackId,
+		Title:      slack.NewTextBlockObject("plain_text", "Search issues", false, false),
+		Submit:     slack.NewTextBlockObject("plain_text", "Search", false, false),
+		Close:      slack.NewTextBlockObject("plain_text", "Close", false, false),
+		Blocks:     slack.Blocks{BlockSet: append([]slack.Block{input}, blocks...)},
+	}
+}
+
+func searchModalQuery(callback slack.InteractionCallback) string {
+	if callback.View.State == nil {
+		return ""
+	}
+
+	return callback.View.State.Values[searchModalQueryBlockId][searchModalQueryActionId].Value
+}
+
+func searchingBlock() *slack.ContextBlock {
+	return slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", "⏳ Searching…", false, false))
+}
+
+func onOpenSearchModal(callback slack.InteractionCallback, config config.Config, ctx context.Context) error {
+	_, err := newSlackClient(config).OpenViewContext(ctx, callback.TriggerID, searchModal(""))
+
+	return err
+}
+
+// onSearchModalSubmission runs the search and shows the results below the search box of the modal, which was
+// put into a searching state by InteractionAck.
+func onSearchModalSubmission(callback slack.InteractionCallback, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	query := searchModalQuery(callback)
+
+	logger.Debugf("Try to find recommendations for App Home query of user %s", callback.User.ID)
+
+	filter, err := homeSearchFilter(config)
+	if err != nil {
+		return err
+	}
+
+	result, err := search.Search(query, query, filter, config, ctx)
+	if err != nil {
+		return err
+	}
+
+	recordQuery(callback.User.ID, "", query, result, config, ctx)
+
+	blocks := []slack.Block{slack.NewDividerBlock(), noResultsSectionBlock(defaultLanguage)}
+	if len(result.Hits.Hits) > 0 {
+		blocks = []slack.Block{slack.NewDividerBlock(), headerSectionBlock(defaultLanguage), resultListSectionBlock(result)}
+	}
+
+	_, err = newSlackClient(config).UpdateViewContext(ctx, searchModal(query, blocks...), "", "", callback.View.ID)
+
+	return err
+}
+
+// homeSearchFilter uses the "*" entry of SLACK_CHANNEL_CONFIG, as the App Home does not belong to a channel. It never
+// shows internal documents, guests of the workspace can open it as well.
+func homeSearchFilter(config config.Config) (search.SearchFilter, error) {
+	channelConfig, err := staticChannelConfig("*", config)
+	if err != nil {
+		return search.SearchFilter{}, err
+	}
+
+	filter := channelConfig.SearchFilter()
+	filter.OnlyPublic = true
+
+	return filter, nil
+}
@@ -0,0 +1,81 @@
+package slack_connector
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/dry_run"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+func TestHomeSearchFilterOnlyFindsPublicDocuments(t *testing.T) {
+	filter, err := homeSearchFilter(config.Config{SlackChannelConfig: `{"C1": {"sources": ["jira"]}, "*": {"sources": ["github"], "maxResults": 3}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := search.SearchFilter{Sources: []string{"github"}, OnlyPublic: true, Size: 3}
+	if !reflect.DeepEqual(filter, expected) {
+		t.Errorf("expected %+v, got %+v", expected, filter)
+	}
+
+	if filter, err := homeSearchFilter(config.Config{}); err != nil || !filter.OnlyPublic {
+		t.Errorf("expected a public filter without SLACK_CHANNEL_CONFIG, got %+v, %v", filter, err)
+	}
+}
+
+func TestClusterCacheRefreshesInTheBackground(t *testing.T) {
+	loads := make(chan error)
+
+	cache := newClusterCache(func(config config.Config, ctx context.Context) ([]dry_run.Cluster, error) {
+		if err := <-loads; err != nil {
+			return nil, err
+		}
+
+		return []dry_run.Cluster{{Score: 2.5}}, nil
+	})
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	refreshed := func() {
+		for {
+			cache.lock.Lock()
+			refreshing := cache.refreshing
+			cache.lock.Unlock()
+
+			if !refreshing {
+				return
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// The first call must not wait for the clusters.
+	if clusters, loaded := cache.Get(config.Config{}, context.Background()); loaded || clusters != nil {
+		t.Errorf("expected no clusters before the first load, got %v", clusters)
+	}
+
+	loads <- nil
+	refreshed()
+
+	if clusters, loaded := cache.Get(config.Config{}, context.Background()); !loaded || len(clusters) != 1 {
+		t.Errorf("expected the loaded clusters, got %v", clusters)
+	}
+
+	now = now.Add(homeClustersTTL)
+	cache.Get(config.Config{}, context.Background())
+
+	loads <- errors.New("search failed")
+	refreshed()
+
+	// The failed refresh keeps the clusters and is not retried on the next call.
+	if clusters, loaded := cache.Get(config.Config{}, context.Background()); !loaded || len(clusters) != 1 {
+		t.Errorf("expected the previous clusters after a failed refresh, got %v", clusters)
+	}
+}
@@ -0,0 +1,47 @@
+package slack_connector
+
+import (
+	"context"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/slack-go/slack"
+)
+
+// InteractionAck is the immediate answer to an interaction, Slack expects it within 3 seconds. A search modal
+// submission keeps the modal open in a searching state, all other interactions are acknowledged without payload.
+func InteractionAck(callback slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	if callback.Type != slack.InteractionTypeViewSubmission || callback.View.CallbackID != SearchModalCallbackId {
+		return nil
+	}
+
+	modal := searchModal(searchModalQuery(callback), searchingBlock())
+
+	return slack.NewUpdateViewSubmissionResponse(&modal)
+}
+
+// OnInteraction handles button clicks and modal submissions after they were acknowledged with InteractionAck.
+func OnInteraction(callback slack.InteractionCallback, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	switch callback.Type {
+	case slack.InteractionTypeBlockActions:
+		for _, action := range callback.ActionCallback.BlockActions {
+			if action.ActionID == OpenSearchModalActionId {
+				return onOpenSearchModal(callback, config, ctx)
+			}
+		}
+
+		return nil
+	case slack.InteractionTypeViewSubmission:
+		if callback.View.CallbackID != SearchModalCallbackId {
+			logger.Debugf("Ignoring unknown view submission: %s", callback.View.CallbackID)
+			return nil
+		}
+
+		return onSearchModalSubmission(callback, config, ctx)
+	default:
+		logger.Debugf("Ignoring unsupported interaction type: %s", callback.Type)
+		return nil
+	}
+}
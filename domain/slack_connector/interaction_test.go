@@ -0,0 +1,29 @@
+package slack_connector
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestInteractionAck(t *testing.T) {
+	submission := slack.InteractionCallback{Type: slack.InteractionTypeViewSubmission}
+	submission.View.CallbackID = SearchModalCallbackId
+	submission.View.State = &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+		searchModalQueryBlockId: {searchModalQueryActionId: {Value: "Paging is broken"}},
+	}}
+
+	response := InteractionAck(submission)
+	if response == nil || response.ResponseAction != slack.RAUpdate {
+		t.Fatalf("expected the search modal to stay open while searching, got %+v", response)
+	}
+
+	input := response.View.Blocks.BlockSet[0].(*slack.InputBlock).Element.(*slack.PlainTextInputBlockElement)
+	if input.InitialValue != "Paging is broken" {
+		t.Errorf("expected the query to be kept, got %q", input.InitialValue)
+	}
+
+	if response := InteractionAck(slack.InteractionCallback{Type: slack.InteractionTypeBlockActions}); response != nil {
+		t.Errorf("expected button clicks to be acknowledged without payload, got %+v", response)
+	}
+}
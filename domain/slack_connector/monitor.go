@@ -0,0 +1,229 @@
+package slack_connector
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// DefaultMonitorMinScore is the minimum score for unsolicited suggestions, it is deliberately higher
+// than search.DefaultMinScore as nobody asked the bot for help.
+const DefaultMonitorMinScore = 2.5
+
+// minQuestionWords filters out short chatter like "thanks!" before running a search.
+const minQuestionWords = 4
+
+// dismissBlockIdPrefix marks the dismiss hint of automatic suggestions, followed by the user who may dismiss them.
+const dismissBlockIdPrefix = "dismiss:"
+
+var (
+	monitorLimiter = newChannelRateLimiter()
+
+	botUserIdLock sync.Mutex
+	botUserId     string
+)
+
+// OnChannelMessage answers new top-level messages in monitored channels with suggestions,
+// but only when the suggestions have a high confidence. Direct messages are never monitored.
+func OnChannelMessage(event *slackevents.MessageEvent, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	if event.SubType != "" || event.BotID != "" || event.ThreadTimeStamp != "" {
+		return nil
+	}
+
+	if event.ChannelType == "im" || event.ChannelType == "mpim" {
+		return nil
+	}
+
+	if len(strings.Fields(event.Text)) < minQuestionWords {
+		return nil
+	}
+
+	channelConfig, err := channelConfigs.Get(event.Channel, config, ctx)
+	if err != nil {
+		return err
+	}
+
+	if !channelConfig.Monitor {
+		return nil
+	}
+
+	api := newSlackClient(config)
+
+	userId, err := getBotUserId(api, ctx)
+	if err != nil {
+		return err
+	}
+
+	// Mentions are answered by OnMention already.
+	if strings.Contains(event.Text, "<@"+userId+">") {
+		return nil
+	}
+
+	if !monitorLimiter.Allow(event.Channel, config.SlackMonitorInterval) {
+		logger.Debugf("Skipping message %s, channel %s is rate limited", event.TimeStamp, event.Channel)
+		return nil
+	}
+
+	filter := channelConfig.SearchFilter()
+	filter.MinScore = channelConfig.MonitorMinScore
+	if filter.MinScore == 0 {
+		filter.MinScore = DefaultMonitorMinScore
+	}
+
+	result, err := search.Search(event.Text, event.Text, filter, config, ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Hits.Hits) == 0 {
+		logger.Debugf("Did not find any confident recommendations for message: %s", event.TimeStamp)
+		monitorLimiter.Reset(event.Channel)
+		return nil
+	}
+
+	logger.Debugf("Found %d confident recommendations for message %s", len(result.Hits.Hits), event.TimeStamp)
+
+	_, _, err = api.PostMessageContext(
+		ctx,
+		event.Channel,
+		slack.MsgOptionBlocks(
+			headerSectionBlock(channelConfig.Language),
+			resultListSectionBlock(result),
+			dismissHintBlock(config.SlackDismissReaction, event.User),
+		),
+		slack.MsgOptionAsUser(true),
+		slack.MsgOptionTS(event.TimeStamp),
+	)
+
+	return err
+}
+
+// OnReactionAdded removes an automatic suggestion of the bot when the poster of the answered message reacts with
+// the dismiss reaction. Replies to mentions and commands are never removed.
+func OnReactionAdded(event *slackevents.ReactionAddedEvent, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	if event.Reaction != config.SlackDismissReaction || event.Item.Type != "message" {
+		return nil
+	}
+
+	api := newSlackClient(config)
+
+	userId, err := getBotUserId(api, ctx)
+	if err != nil {
+		return err
+	}
+
+	if event.ItemUser != userId {
+		return nil
+	}
+
+	messages, _, _, err := api.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+		ChannelID: event.Item.Channel,
+		Timestamp: event.Item.Timestamp,
+		Latest:    event.Item.Timestamp,
+		Oldest:    event.Item.Timestamp,
+		Inclusive: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	dismissible := false
+	for _, message := range messages {
+		if message.Timestamp == event.Item.Timestamp && suggestionPoster(message) == event.User {
+			dismissible = true
+		}
+	}
+
+	if !dismissible {
+		logger.Debugf("User %s may not dismiss message %s in channel %s", event.User, event.Item.Timestamp, event.Item.Channel)
+		return nil
+	}
+
+	logger.Debugf("User %s dismissed message %s in channel %s", event.User, event.Item.Timestamp, event.Item.Channel)
+
+	_, _, err = api.DeleteMessageContext(ctx, event.Item.Channel, event.Item.Timestamp)
+
+	return err
+}
+
+func dismissHintBlock(reaction string, poster string) *slack.ContextBlock {
+	return slack.NewContextBlock(dismissBlockIdPrefix+poster, slack.NewTextBlockObject(
+		"mrkdwn",
+		"Not helpful? React with :"+reaction+": to remove this message.",
+		false,
+		false,
+	))
+}
+
+// suggestionPoster returns the user who may dismiss an automatic suggestion, it is empty for all other messages.
+func suggestionPoster(message slack.Message) string {
+	for _, block := range message.Blocks.BlockSet {
+		if hint, ok := block.(*slack.ContextBlock); ok && strings.HasPrefix(hint.BlockID, dismissBlockIdPrefix) {
+			return strings.TrimPrefix(hint.BlockID, dismissBlockIdPrefix)
+		}
+	}
+
+	return ""
+}
+
+func getBotUserId(api *slack.Client, ctx context.Context) (string, error) {
+	botUserIdLock.Lock()
+	defer botUserIdLock.Unlock()
+
+	if botUserId != "" {
+		return botUserId, nil
+	}
+
+	auth, err := api.AuthTestContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	botUserId = auth.UserID
+
+	return botUserId, nil
+}
+
+// channelRateLimiter allows one action per channel in a given interval.
+type channelRateLimiter struct {
+	lock sync.Mutex
+	last map[string]time.Time
+	now  func() time.Time
+}
+
+func newChannelRateLimiter() *channelRateLimiter {
+	return &channelRateLimiter{last: map[string]time.Time{}, now: time.Now}
+}
+
+func (l *channelRateLimiter) Allow(channel string, interval time.Duration) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	if last, ok := l.last[channel]; ok && now.Sub(last) < interval {
+		return false
+	}
+
+	l.last[channel] = now
+
+	return true
+}
+
+// Reset gives the slot back, e.g. when no suggestion was posted after all.
+func (l *channelRateLimiter) Reset(channel string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.last, channel)
+}
@@ -0,0 +1,75 @@
+package slack_connector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestChannelRateLimiter(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newChannelRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	if !limiter.Allow("C1", time.Minute) {
+		t.Fatal("expected first message in channel to be allowed")
+	}
+
+	if limiter.Allow("C1", time.Minute) {
+		t.Error("expected second message in channel to be rate limited")
+	}
+
+	if !limiter.Allow("C2", time.Minute) {
+		t.Error("expected other channels not to be rate limited")
+	}
+
+	now = now.Add(time.Minute)
+
+	if !limiter.Allow("C1", time.Minute) {
+		t.Error("expected message to be allowed after the interval")
+	}
+
+	limiter.Reset("C1")
+
+	if !limiter.Allow("C1", time.Minute) {
+		t.Error("expected message to be allowed after a reset")
+	}
+}
+
+func TestSuggestionPoster(t *testing.T) {
+	suggestion := slack.NewBlockMessage(headerSectionBlock("en"), dismissHintBlock("x", "U1"))
+	reply := slack.NewBlockMessage(headerSectionBlock("en"))
+
+	for expected, message := range map[string]slack.Message{"U1": suggestion, "": reply} {
+		// The blocks are read from the Slack API, so the poster has to survive the JSON round trip.
+		data, err := json.Marshal(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded slack.Message
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		if poster := suggestionPoster(decoded); poster != expected {
+			t.Errorf("expected poster %q, got %q", expected, poster)
+		}
+	}
+}
+
+func TestOnChannelMessageIgnoresDirectMessages(t *testing.T) {
+	for _, channelType := range []string{"im", "mpim"} {
+		event := &slackevents.MessageEvent{Channel: "D1", ChannelType: channelType, Text: "how do I fix the empty cart"}
+
+		// Without an OpenSearch client the channel config lookup would fail, so the message has to be skipped before.
+		if err := OnChannelMessage(event, config.Config{}, context.Background()); err != nil {
+			t.Errorf("expected %s messages to be ignored, got %s", channelType, err)
+		}
+	}
+}
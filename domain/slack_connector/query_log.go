@@ -0,0 +1,122 @@
+package slack_connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+)
+
+// Query is a search somebody ran in Slack, it powers the recent queries and most suggested issues on the App Home.
+type Query struct {
+	UserId      string   `json:"userId"`
+	ChannelId   string   `json:"channelId"`
+	Query       string   `json:"query"`
+	Date        int64    `json:"date"`
+	HitIds      []string `json:"hitIds"`
+	TopHitId    string   `json:"topHitId,omitempty"`
+	TopHitTitle string   `json:"topHitTitle,omitempty"`
+	TopHitLink  string   `json:"topHitLink,omitempty"`
+}
+
+// recordQuery stores the query in the query log, failures are only logged as the query log is not essential.
+func recordQuery(userId string, channelId string, text string, result *search.SearchResponse, config config.Config, ctx context.Context) {
+	logger := logging.FromContext(ctx)
+
+	query := Query{
+		UserId:    userId,
+		ChannelId: channelId,
+		Query:     text,
+		Date:      time.Now().Unix(),
+		HitIds:    []string{},
+	}
+
+	for _, hit := range result.Hits.Hits {
+		query.HitIds = append(query.HitIds, hit.ID)
+	}
+
+	if len(result.Hits.Hits) > 0 {
+		query.TopHitId = result.Hits.Hits[0].ID
+		query.TopHitTitle = result.Hits.Hits[0].Source.Title
+		query.TopHitLink = hitLink(result.Hits.Hits[0])
+	}
+
+	jsonString, _ := json.Marshal(query)
+
+	req := opensearchapi.IndexRequest{
+		Index: queryLogIndex(config),
+		Body:  bytes.NewReader(jsonString),
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.IndexTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		logger.Errorf("Error while recording Slack query: %s", err)
+		return
+	}
+
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		body, _ := io.ReadAll(resp.Body)
+		logger.Errorf("Error while recording Slack query: %s", body)
+	}
+}
+
+func RecentQueries(userId string, size int, config config.Config, ctx context.Context) ([]Query, error) {
+	userIdJson, _ := json.Marshal(userId)
+
+	req := opensearchapi.SearchRequest{
+		Index: []string{queryLogIndex(config)},
+		Body: strings.NewReader(fmt.Sprintf(`{
+			"size": %d,
+			"sort": [{ "date": "desc" }],
+			"query": { "term": { "userId.keyword": %s }}
+		}`, size, userIdJson)),
+		IgnoreUnavailable: opensearchapi.BoolPtr(true),
+	}
+
+	ctx, cancel := config.WithTimeout(ctx, config.SearchTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, config.OpensearchClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recent queries: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source Query `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode opensearch response: %w", err)
+	}
+
+	queries := make([]Query, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		queries = append(queries, hit.Source)
+	}
+
+	return queries, nil
+}
+
+func queryLogIndex(config config.Config) string {
+	return config.IndexName + "-slack-queries"
+}
@@ -2,26 +2,68 @@ package slack_connector
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
 	"github.com/slack-go/slack"
 )
 
-func noResultsSectionBlock() *slack.SectionBlock {
+const defaultLanguage = "en"
+
+type translation struct {
+	NoResults string
+	Header    string
+}
+
+var translations = map[string]translation{
+	"en": {
+		NoResults: "✅ We didn't find any existing issues that are related to your topic",
+		Header:    "🤖 We found the following existing issues which may help or are related to your topic: ",
+	},
+	"de": {
+		NoResults: "✅ Wir haben keine bestehenden Issues zu deinem Thema gefunden",
+		Header:    "🤖 Wir haben folgende bestehende Issues gefunden, die dir helfen könnten oder mit deinem Thema zusammenhängen: ",
+	},
+}
+
+// newSlackClient creates a client for the bot, its requests show up in the outbound request metrics
+// and are limited to SLACK_TIMEOUT.
+func newSlackClient(config config.Config) *slack.Client {
+	return slack.New(config.SlackBotToken, slack.OptionHTTPClient(newSlackHTTPClient(config)))
+}
+
+func newSlackHTTPClient(config config.Config) *http.Client {
+	httpClient := metrics.HTTPClient("slack")
+	httpClient.Timeout = config.SlackTimeout
+
+	return httpClient
+}
+
+func languageOrDefault(language string) string {
+	if _, ok := translations[language]; ok {
+		return language
+	}
+
+	return defaultLanguage
+}
+
+func noResultsSectionBlock(language string) *slack.SectionBlock {
 	noIssuesText := slack.NewTextBlockObject(
 		"mrkdwn",
-		"✅ We didn't find any existing issues that are related to your topic",
+		translations[languageOrDefault(language)].NoResults,
 		false,
 		false,
 	)
 	return slack.NewSectionBlock(noIssuesText, nil, nil)
 }
 
-func headerSectionBlock() *slack.SectionBlock {
+func headerSectionBlock(language string) *slack.SectionBlock {
 	headerText := slack.NewTextBlockObject(
 		"mrkdwn",
-		"🤖 We found the following existing issues which may help or are related to your topic: ",
+		translations[languageOrDefault(language)].Header,
 		false,
 		false,
 	)
@@ -31,16 +73,21 @@ func headerSectionBlock() *slack.SectionBlock {
 func resultListSectionBlock(result *search.SearchResponse) *slack.SectionBlock {
 	output := strings.Builder{}
 	for _, hit := range result.Hits.Hits {
-		switch hit.Source.Source {
-		case "jira":
-			output.WriteString(fmt.Sprintf("• <%s|%s>\n", "https://shopware.atlassian.net/browse/"+hit.ID, hit.Source.Title))
-		case "github":
-			link := "https://github.com/shopware/platform/issues/" + strings.Replace(hit.ID, "GH-", "", 1)
-			output.WriteString(fmt.Sprintf("• <%s|%s>\n", link, hit.Source.Title))
-		}
+		output.WriteString(fmt.Sprintf("• <%s|%s>\n", hitLink(hit), hit.Source.Title))
 	}
 
 	listText := slack.NewTextBlockObject("mrkdwn", output.String(), false, false)
 	listSection := slack.NewSectionBlock(listText, nil, nil)
 	return listSection
 }
+
+func hitLink(hit search.IssueResult) string {
+	switch hit.Source.Source {
+	case "jira":
+		return "https://shopware.atlassian.net/browse/" + hit.ID
+	case "github":
+		return "https://github.com/shopware/platform/issues/" + strings.Replace(hit.ID, "GH-", "", 1)
+	default:
+		return hit.Source.Link
+	}
+}
@@ -0,0 +1,119 @@
+package slack_connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/slack-go/slack"
+)
+
+// OnSlashCommand dispatches a slash command to its handler, independent of the transport it was received on.
+func OnSlashCommand(command slack.SlashCommand, config config.Config, ctx context.Context) (slack.Message, error) {
+	switch command.Command {
+	case "/issues", "/aiaiai":
+		args := strings.Fields(command.Text)
+		if len(args) > 0 && args[0] == "config" {
+			return OnConfigCommand(command, args[1:], config, ctx)
+		}
+
+		return OnIssuesCommand(command, config, ctx)
+	default:
+		return slack.Message{}, fmt.Errorf("unknown slash command: %s", command.Command)
+	}
+}
+
+// RespondToSlashCommand handles a slash command which was already acknowledged and posts the answer to its
+// response_url, Slack only waits 3 seconds for the acknowledgement.
+func RespondToSlashCommand(command slack.SlashCommand, config config.Config, ctx context.Context) error {
+	message, err := OnSlashCommand(command, config, ctx)
+	if err != nil {
+		message = ephemeralMessage("⚠️ Something went wrong, please try again later")
+	}
+
+	if postErr := slack.PostWebhookCustomHTTPContext(ctx, command.ResponseURL, newSlackHTTPClient(config), &slack.WebhookMessage{
+		Text:         message.Text,
+		Blocks:       &message.Blocks,
+		ResponseType: message.ResponseType,
+	}); postErr != nil {
+		return fmt.Errorf("failed to answer slash command: %w", postErr)
+	}
+
+	return err
+}
+
+// OnConfigCommand shows or changes the channel config, e.g. `/issues config sources=github,jira only_public=true`.
+// `/issues config reset` removes the stored config, so the one from SLACK_CHANNEL_CONFIG applies again.
+func OnConfigCommand(command slack.SlashCommand, args []string, config config.Config, ctx context.Context) (slack.Message, error) {
+	logger := logging.FromContext(ctx)
+
+	if len(args) == 0 {
+		channelConfig, err := GetChannelConfig(command.ChannelID, config, ctx)
+		if err != nil {
+			return slack.Message{}, err
+		}
+
+		return ephemeralMessage(fmt.Sprintf("Current config for this channel: `%s`", channelConfig)), nil
+	}
+
+	isAdmin, err := isSlackAdmin(command.UserID, config, ctx)
+	if err != nil {
+		return slack.Message{}, err
+	}
+
+	if !isAdmin {
+		logger.Debugf("User %s is not allowed to change the config of channel %s", command.UserID, command.ChannelID)
+		return ephemeralMessage("⛔ Only admins can change the config of this channel"), nil
+	}
+
+	if len(args) == 1 && args[0] == "reset" {
+		if err := DeleteChannelConfig(command.ChannelID, config, ctx); err != nil {
+			return slack.Message{}, err
+		}
+
+		logger.Infof("User %s reset the config of channel %s", command.UserID, command.ChannelID)
+
+		return ephemeralMessage("Config for this channel has been reset"), nil
+	}
+
+	channelConfig, err := GetChannelConfig(command.ChannelID, config, ctx)
+	if err != nil {
+		return slack.Message{}, err
+	}
+
+	channelConfig, err = parseChannelConfigArgs(channelConfig, args)
+	if err != nil {
+		return ephemeralMessage(fmt.Sprintf("⚠️ %s. Supported settings: `sources`, `only_public`, `min_score`, `max_results`, `language`, `monitor`, `monitor_min_score`", err)), nil
+	}
+
+	if err := SaveChannelConfig(command.ChannelID, channelConfig, config, ctx); err != nil {
+		return slack.Message{}, err
+	}
+
+	logger.Infof("User %s changed the config of channel %s to: %s", command.UserID, command.ChannelID, channelConfig)
+
+	return ephemeralMessage(fmt.Sprintf("Config for this channel has been updated: `%s`", channelConfig)), nil
+}
+
+func isSlackAdmin(userId string, config config.Config, ctx context.Context) (bool, error) {
+	if lo.Contains(config.SlackAdminUsers, userId) {
+		return true, nil
+	}
+
+	user, err := newSlackClient(config).GetUserInfoContext(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+
+	return user.IsAdmin || user.IsOwner, nil
+}
+
+func ephemeralMessage(text string) slack.Message {
+	message := slack.NewBlockMessage(slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil))
+	message.ResponseType = slack.ResponseTypeEphemeral
+
+	return message
+}
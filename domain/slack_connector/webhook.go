@@ -1,34 +1,46 @@
 package slack_connector
 
 import (
+	"context"
+
 	"github.com/shopwarelabs/jira-issue-bot/domain/search"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
 	"github.com/slack-go/slack"
-	"go.uber.org/zap"
 )
 
-func OnIssuesCommand(command slack.SlashCommand, config config.Config, logger *zap.SugaredLogger) (slack.Message, error) {
+func OnIssuesCommand(command slack.SlashCommand, config config.Config, ctx context.Context) (slack.Message, error) {
+	logger := logging.FromContext(ctx)
+
 	logger.Debugf("Try to find recommendations for message: %s", command.TriggerID)
 
+	channelConfig, err := GetChannelConfig(command.ChannelID, config, ctx)
+	if err != nil {
+		return slack.Message{}, err
+	}
+
 	result, err := search.Search(
 		command.Text,
 		command.Text,
-		search.SearchFilter{},
+		channelConfig.SearchFilter(),
 		config,
+		ctx,
 	)
 
 	if err != nil {
 		return slack.Message{}, err
 	}
 
+	recordQuery(command.UserID, command.ChannelID, command.Text, result, config, ctx)
+
 	if len(result.Hits.Hits) == 0 {
 		logger.Debugf("Did not found any recommendation for message: %s", command.TriggerID)
-		return slack.NewBlockMessage(noResultsSectionBlock()), nil
+		return slack.NewBlockMessage(noResultsSectionBlock(channelConfig.Language)), nil
 	}
 
 	logger.Debugf("Found %d recommendations for message %s", len(result.Hits.Hits), command.TriggerID)
 
-	headerSection := headerSectionBlock()
+	headerSection := headerSectionBlock(channelConfig.Language)
 	listSection := resultListSectionBlock(result)
 
 	return slack.NewBlockMessage(headerSection, listSection), nil
@@ -0,0 +1,71 @@
+package stack_overflow_connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+const tag = "shopware6"
+
+// Connector indexes the Stack Overflow questions tagged with shopware6.
+type Connector struct{}
+
+func (Connector) Name() string {
+	return "stack-overflow"
+}
+
+// Fetch pages through all questions, or only through the questions with activity since the given time.
+// The activity includes edits, answers and comments.
+func (Connector) Fetch(ctx context.Context, config config.Config, since time.Time, yield func(item json.RawMessage) error) error {
+	sorting := "creation"
+	var min int64
+	if !since.IsZero() {
+		sorting = "activity"
+		min = since.Unix()
+	}
+
+	for page := 1; ; page++ {
+		questions, err := getQuestions(page, sorting, tag, min, ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, question := range questions.Items {
+			data, err := json.Marshal(question)
+			if err != nil {
+				return err
+			}
+
+			if err := yield(data); err != nil {
+				return err
+			}
+		}
+
+		if !questions.HasMore {
+			return nil
+		}
+
+		// The API asks to wait before the next request when it is under load.
+		if questions.Backoff > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(questions.Backoff) * time.Second):
+			}
+		}
+	}
+}
+
+func (Connector) Map(item json.RawMessage) (string, search.Document, error) {
+	var question StackoverflowListingElement
+	if err := json.Unmarshal(item, &question); err != nil {
+		return "", search.Document{}, err
+	}
+
+	return fmt.Sprintf("SO-%d", question.QuestionId), questionDocument(&question), nil
+}
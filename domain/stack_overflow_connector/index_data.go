@@ -9,17 +9,30 @@ import (
 
 	"github.com/shopwarelabs/jira-issue-bot/domain/search"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
-	"go.uber.org/zap"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
 )
 
+var httpClient = metrics.HTTPClient("stackexchange")
+
 func GetQuestions(page int, sorting string, tag string, ctx context.Context) (*StackoverflowListingCollection, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://api.stackexchange.com/2.3/questions?page=%d&order=desc&sort=%s&tagged=%s&site=stackoverflow&filter=!nOedRLb*F(&pagesize=100", page, sorting, tag), nil)
+	return getQuestions(page, sorting, tag, 0, ctx)
+}
+
+// getQuestions only returns questions whose sort field is at least min, e.g. the last activity for sort "activity".
+func getQuestions(page int, sorting string, tag string, min int64, ctx context.Context) (*StackoverflowListingCollection, error) {
+	url := fmt.Sprintf("https://api.stackexchange.com/2.3/questions?page=%d&order=desc&sort=%s&tagged=%s&site=stackoverflow&filter=!nOedRLb*F(&pagesize=100", page, sorting, tag)
+	if min > 0 {
+		url += fmt.Sprintf("&min=%d", min)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -45,13 +58,32 @@ func GetQuestions(page int, sorting string, tag string, ctx context.Context) (*S
 	return &collection, nil
 }
 
-func IndexSingleStackOverflowQuestion(question *StackoverflowListingElement, config config.Config, logger *zap.SugaredLogger) error {
+func IndexSingleStackOverflowQuestion(question *StackoverflowListingElement, config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	err := search.IndexDocument(fmt.Sprintf("SO-%d", question.QuestionId), questionDocument(question), config, ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Indexed StackOverflow question: %d", question.QuestionId)
+
+	return nil
+}
+
+func questionDocument(question *StackoverflowListingElement) search.Document {
 	state := "open"
 	if question.IsAnswered {
 		state = "closed"
 	}
 
-	document := search.Document{
+	// A downvoted question has a negative score, it counts as no reaction like the other sources.
+	reactions := question.Score
+	if reactions < 0 {
+		reactions = 0
+	}
+
+	return search.Document{
 		Title:        question.Title,
 		Description:  search.CleanupString(question.Body),
 		Status:       state,
@@ -65,16 +97,8 @@ func IndexSingleStackOverflowQuestion(question *StackoverflowListingElement, con
 		AuthorLink:   question.Owner.Link,
 		DateCreated:  question.CreationDate,
 		Labels:       question.Tags,
+		Reactions:    reactions,
 	}
-
-	err := search.IndexDocument(fmt.Sprintf("SO-%d", question.QuestionId), document, config)
-	if err != nil {
-		return err
-	}
-
-	logger.Debugf("Indexed StackOverflow question: %d", question.QuestionId)
-
-	return nil
 }
 
 type StackoverflowListingCollection struct {
@@ -82,6 +106,7 @@ type StackoverflowListingCollection struct {
 	HasMore        bool                          `json:"has_more"`
 	QuotaMax       int                           `json:"quota_max"`
 	QuotaRemaining int                           `json:"quota_remaining"`
+	Backoff        int                           `json:"backoff,omitempty"`
 }
 
 type StackoverflowListingElement struct {
@@ -0,0 +1,11 @@
+package stack_overflow_connector
+
+import "testing"
+
+func TestQuestionDocumentReactions(t *testing.T) {
+	for score, reactions := range map[int]int{-3: 0, 0: 0, 7: 7} {
+		if document := questionDocument(&StackoverflowListingElement{Score: score}); document.Reactions != reactions {
+			t.Errorf("expected %d reactions for score %d, got %d", reactions, score, document.Reactions)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/health"
+
+	"go.uber.org/zap"
+)
+
+// registerHealthHandlers registers /healthz, which only tells that the process is up, and /readyz,
+// which tells whether searches can be answered.
+func registerHealthHandlers(mux *http.ServeMux, checker *health.Checker, logger *zap.SugaredLogger) {
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		JSONResp(w, struct {
+			Status string `json:"status"`
+		}{Status: "ok"}, logger)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Readiness(r.Context())
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if !report.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		JSONResp(w, report, logger)
+	})
+}
@@ -1,93 +1,172 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
 	"os"
-	"path/filepath"
 	"strings"
-	"sync"
 
-	"github.com/samber/lo"
-	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/domain/acceptance"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
 	"github.com/spf13/cobra"
 )
 
+var acceptanceOptions struct {
+	dir        string
+	k          int
+	jsonReport string
+	junit      string
+	thresholds acceptance.Thresholds
+	profiles   []string
+	changes    int
+}
+
 var acceptanceCommand = &cobra.Command{
 	Use:   "test",
 	Short: "Run the acceptance test suite to see how good our search is performing",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := cmd.Context().Value(ConfigKey{}).(config.Config)
 
-		files, err := os.ReadDir("duplicates")
+		if acceptanceOptions.k <= 0 {
+			return fmt.Errorf("k must be positive")
+		}
+
+		cases, err := acceptance.LoadDataset(acceptanceOptions.dir)
 		if err != nil {
-			return fmt.Errorf("failed reading directory: %w", err)
+			return err
+		}
+
+		if len(acceptanceOptions.profiles) > 0 {
+			return compareProfiles(cmd, cases, cfg)
 		}
 
-		var wg sync.WaitGroup
-
-		for _, file := range files {
-			var issue Issue
-
-			readFile, _ := os.ReadFile("duplicates/" + file.Name())
-			if err := json.Unmarshal(readFile, &issue); err != nil {
-				log.Println(err)
-				os.Exit(1)
-			}
-			issue.GithubIssue = "GH-" + file.Name()[:len(file.Name())-len(filepath.Ext(file.Name()))]
-
-			wg.Add(1)
-
-			go func(issue Issue) {
-				var matches []string
-
-				result, err := search.Search(
-					issue.Title,
-					issue.Description,
-					search.SearchFilter{ExcludedDocumentId: issue.GithubIssue},
-					cfg,
-				)
-				if err != nil {
-					log.Println("search failed", err)
-					os.Exit(1)
-				}
-
-				for _, hit := range result.Hits.Hits {
-					matches = append(matches, hit.ID)
-				}
-
-				expected, actual := lo.Difference(issue.Matches, matches)
-
-				if len(expected) > 0 {
-					log.Printf("Expected that jira issues \"%s\" to be found for issue \"%s\"\n", strings.Join(expected, ", "), issue.GithubIssue)
-				}
-				if len(actual) > 0 {
-					log.Printf("Unexpected jira issues \"%s\" found for issue \"%s\"\n", strings.Join(actual, ", "), issue.GithubIssue)
-				}
-
-				defer wg.Done()
-			}(issue)
+		report := acceptance.Evaluate(cases, acceptanceOptions.k, acceptance.DefaultSearch(acceptanceOptions.k, cfg), cmd.Context())
+
+		if err := acceptance.WriteSummary(cmd.OutOrStdout(), report, acceptanceOptions.thresholds); err != nil {
+			return err
 		}
 
-		wg.Wait()
+		if err := writeReports(report, []acceptance.Report{report}); err != nil {
+			return err
+		}
+
+		if failures := report.Failures(acceptanceOptions.thresholds); len(failures) > 0 {
+			return fmt.Errorf("acceptance test failed: %s", strings.Join(failures, ", "))
+		}
 
 		return nil
 	},
 }
 
+// compareProfiles evaluates the dataset with every profile, the first profile is the baseline of the comparison.
+func compareProfiles(cmd *cobra.Command, cases []acceptance.Case, cfg config.Config) error {
+	// Load all profiles first, so a typo in the last one does not waste the searches of the others.
+	var profiles []acceptance.Profile
+	for _, path := range acceptanceOptions.profiles {
+		profile, err := acceptance.LoadProfile(path)
+		if err != nil {
+			return err
+		}
+
+		profiles = append(profiles, profile)
+	}
+
+	var reports []acceptance.Report
+
+	for _, profile := range profiles {
+		report := acceptance.Evaluate(cases, acceptanceOptions.k, acceptance.ProfileSearch(profile, acceptanceOptions.k, cfg), cmd.Context())
+		report.Profile = profile.Name
+
+		reports = append(reports, report)
+	}
+
+	if len(reports) == 1 {
+		if err := acceptance.WriteSummary(cmd.OutOrStdout(), reports[0], acceptanceOptions.thresholds); err != nil {
+			return err
+		}
+	} else if err := acceptance.WriteComparison(cmd.OutOrStdout(), reports, acceptanceOptions.changes); err != nil {
+		return err
+	}
+
+	if err := writeReports(reports, reports); err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, report := range reports {
+		for _, failure := range report.Failures(acceptanceOptions.thresholds) {
+			failures = append(failures, report.Profile+": "+failure)
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("acceptance test failed: %s", strings.Join(failures, ", "))
+	}
+
+	return nil
+}
+
+// writeReports writes the JSON and JUnit reports if they were requested.
+func writeReports(jsonValue any, reports []acceptance.Report) error {
+	if acceptanceOptions.jsonReport != "" {
+		if err := writeReport(acceptanceOptions.jsonReport, func(file *os.File) error {
+			return acceptance.WriteJSON(file, jsonValue)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if acceptanceOptions.junit != "" {
+		if err := writeReport(acceptanceOptions.junit, func(file *os.File) error {
+			return acceptance.WriteJUnit(file, reports, acceptanceOptions.thresholds)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func registerAcceptanceFlags() {
+	flags := acceptanceCommand.Flags()
+	flags.StringVar(&acceptanceOptions.dir, "dir", "duplicates", "directory of the dataset, one <issue number>.json per GitHub issue")
+	flags.IntVarP(&acceptanceOptions.k, "k", "k", 5, "number of hits the metrics are computed on")
+	flags.StringVar(&acceptanceOptions.jsonReport, "json", "", "write the report as JSON to this file")
+	flags.StringVar(&acceptanceOptions.junit, "junit", "", "write the report as JUnit XML to this file")
+	flags.Float64Var(&acceptanceOptions.thresholds.Precision, "min-precision", 0, "fail if precision@k is below this value")
+	flags.Float64Var(&acceptanceOptions.thresholds.Recall, "min-recall", 0, "fail if recall@k is below this value")
+	flags.Float64Var(&acceptanceOptions.thresholds.MRR, "min-mrr", 0, "fail if the mean reciprocal rank is below this value")
+	flags.Float64Var(&acceptanceOptions.thresholds.NDCG, "min-ndcg", 0, "fail if nDCG@k is below this value")
+	flags.StringArrayVar(&acceptanceOptions.profiles, "profile", nil, "evaluate a YAML ranking profile, repeat to compare profiles against the first one")
+	flags.IntVar(&acceptanceOptions.changes, "changes", 10, "number of most changed issues to list per compared profile")
+}
+
+func writeReport(path string, write func(file *os.File) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+
+	return file.Close()
+}
+
 func Register(rootCmd *cobra.Command) {
+	registerAcceptanceFlags()
+	registerTuneFlags()
+	registerDatasetFlags()
+	registerDryRunFlags()
+	registerClustersFlags()
+
 	rootCmd.AddCommand(acceptanceCommand)
+	rootCmd.AddCommand(tuneCommand)
+	rootCmd.AddCommand(datasetCommand)
 	rootCmd.AddCommand(dryRunCommand)
+	rootCmd.AddCommand(clustersCommand)
 	rootCmd.AddCommand(initOpensearchCommand)
 	rootCmd.AddCommand(loadModelCommand)
 	rootCmd.AddCommand(createIndexCommand)
 }
-
-type Issue struct {
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Matches     []string `json:"matches"`
-	GithubIssue string   `json:"omitempty"`
-}
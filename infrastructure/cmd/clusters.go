@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/dry_run"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/spf13/cobra"
+)
+
+var clustersOptions struct {
+	dry_run.Options
+	canonical string
+	output    string
+	format    string
+}
+
+var clustersCommand = &cobra.Command{
+	Use:   "clusters",
+	Short: "Group the open documents which describe the same problem, with a suggested canonical issue per group",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := cmd.Context().Value(ConfigKey{}).(config.Config)
+
+		report, err := dry_run.RunClusters(clustersOptions.Options, clustersOptions.canonical, cfg, cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if clustersOptions.output == "" {
+			return dry_run.WriteClusters(cmd.OutOrStdout(), clustersOptions.format, report)
+		}
+
+		if err := writeReport(clustersOptions.output, func(file *os.File) error {
+			return dry_run.WriteClusters(file, clustersOptions.format, report)
+		}); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %d clusters among %d documents to %s\n", len(report.Clusters), report.Documents, clustersOptions.output)
+
+		return nil
+	},
+}
+
+func registerClustersFlags() {
+	defaults := dry_run.DefaultOptions()
+
+	flags := clustersCommand.Flags()
+	flags.StringSliceVar(&clustersOptions.Sources, "source", nil, "sources of the clustered documents, empty clusters all")
+	flags.StringVar(&clustersOptions.Status, "status", defaults.Status, "status of the clustered documents, empty clusters all")
+	flags.Float64Var(&clustersOptions.MinScore, "min-score", defaults.MinScore, "minimum score which connects two documents")
+	flags.IntVar(&clustersOptions.Size, "neighbours", 10, "number of nearest neighbours searched per document")
+	flags.StringVar(&clustersOptions.canonical, "canonical", dry_run.CanonicalOldest, "pick the canonical issue of a cluster, oldest or reactions")
+	flags.StringVarP(&clustersOptions.output, "output", "o", "", "write the report to this file instead of stdout")
+	flags.StringVar(&clustersOptions.format, "format", "markdown", "report format markdown, json or csv")
+}
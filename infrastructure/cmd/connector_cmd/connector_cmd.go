@@ -0,0 +1,92 @@
+package connector_cmd
+
+import (
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/connector"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/cmd"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/spf13/cobra"
+)
+
+var syncCommand = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch and index changed issues from Platforms",
+}
+
+// Register adds a download, index and sync subcommand for every registered connector.
+func Register(rootCmd *cobra.Command, downloadCommand *cobra.Command, indexCommand *cobra.Command) {
+	rootCmd.AddCommand(syncCommand)
+
+	for _, source := range connector.All() {
+		downloadCommand.AddCommand(newDownloadCommand(source))
+		indexCommand.AddCommand(newIndexCommand(source))
+		syncCommand.AddCommand(newSyncCommand(source))
+	}
+}
+
+func newDownloadCommand(source connector.Connector) *cobra.Command {
+	return &cobra.Command{
+		Use:   source.Name(),
+		Short: "Download all items from " + source.Name(),
+		RunE: func(command *cobra.Command, args []string) error {
+			ctx := command.Context()
+			cfg := ctx.Value(cmd.ConfigKey{}).(config.Config)
+
+			downloaded, err := connector.Download(source, source.Name(), cfg, ctx)
+			if err != nil {
+				return err
+			}
+
+			logging.FromContext(ctx).Infof("Downloaded %d items to %s/", downloaded, source.Name())
+
+			return nil
+		},
+	}
+}
+
+func newIndexCommand(source connector.Connector) *cobra.Command {
+	return &cobra.Command{
+		Use:   source.Name(),
+		Short: "Index all downloaded items of " + source.Name() + " to OpenSearch",
+		RunE: func(command *cobra.Command, args []string) error {
+			ctx := command.Context()
+			cfg := ctx.Value(cmd.ConfigKey{}).(config.Config)
+
+			result, err := connector.IndexFolder(source, source.Name(), cfg, ctx)
+			logging.FromContext(ctx).Infof("Indexed %d items, %d failed", result.Indexed, result.Failed)
+
+			return err
+		},
+	}
+}
+
+func newSyncCommand(source connector.Connector) *cobra.Command {
+	var since time.Duration
+
+	command := &cobra.Command{
+		Use:   source.Name(),
+		Short: "Fetch the items of " + source.Name() + " which changed since the last sync and index them to OpenSearch",
+		RunE: func(command *cobra.Command, args []string) error {
+			ctx := command.Context()
+			cfg := ctx.Value(cmd.ConfigKey{}).(config.Config)
+
+			var result connector.Result
+			var err error
+			if since > 0 {
+				result, err = connector.Sync(source, time.Now().Add(-since), cfg, ctx)
+			} else {
+				result, err = connector.SyncFromCheckpoint(source, cfg, ctx)
+			}
+
+			logging.FromContext(ctx).Infof("Indexed %d items, %d failed", result.Indexed, result.Failed)
+
+			return err
+		},
+	}
+
+	command.Flags().DurationVar(&since, "since", 0, "sync the items changed within this duration, e.g. 24h, instead of using and updating the checkpoint")
+
+	return command
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
-	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/open_search"
 	"github.com/spf13/cobra"
 )
@@ -13,8 +12,7 @@ var createIndexCommand = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
 		cfg := ctx.Value(ConfigKey{}).(config.Config)
-		logger := logging.FromContext(ctx)
 
-		open_search.CreateIndex(cfg, ctx, logger)
+		open_search.CreateIndex(cfg, ctx)
 	},
 }
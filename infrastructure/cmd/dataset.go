@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/shopwarelabs/jira-issue-bot/domain/acceptance"
+	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/spf13/cobra"
+)
+
+var datasetOptions struct {
+	dir     string
+	size    int
+	matches []string
+	since   time.Duration
+	jql     string
+	sources []string
+}
+
+var datasetCommand = &cobra.Command{
+	Use:   "dataset",
+	Short: "Grow the labelled duplicates dataset of the acceptance test",
+}
+
+var datasetAddCommand = &cobra.Command{
+	Use:   "add <issue-id>",
+	Short: "Label the duplicates of an indexed issue with its current top results",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := cmd.Context().Value(ConfigKey{}).(config.Config)
+
+		id, err := acceptance.NormalizeId(args[0])
+		if err != nil {
+			return err
+		}
+
+		c, exists, err := acceptance.LoadCase(datasetOptions.dir, id)
+		if err != nil {
+			return err
+		}
+
+		document, hits, err := acceptance.Candidates(id, datasetOptions.size, cfg, cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		// Keep the labelled title and description, the indexed document might have been edited since.
+		if !exists {
+			c.Title = document.Title
+			c.Description = document.Description
+		}
+
+		var selected []string
+		for _, match := range datasetOptions.matches {
+			id, err := acceptance.NormalizeId(match)
+			if err != nil {
+				return err
+			}
+
+			selected = append(selected, id)
+		}
+
+		if len(selected) == 0 {
+			if selected, err = labelCandidates(cmd.InOrStdin(), cmd.OutOrStdout(), c, hits); err != nil {
+				return err
+			}
+		}
+
+		c.Matches = nil
+		c.AddMatches(selected...)
+
+		if err := acceptance.SaveCase(datasetOptions.dir, c); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s with matches %s\n", acceptance.CaseFile(datasetOptions.dir, id), strings.Join(c.Matches, ", "))
+
+		return nil
+	},
+}
+
+// labelCandidates lists the hits and asks which of them are duplicates, the current matches are preselected.
+func labelCandidates(in io.Reader, out io.Writer, c acceptance.Case, hits []search.IssueResult) ([]string, error) {
+	fmt.Fprintf(out, "%s: %s\n\n", c.Id, c.Title)
+
+	candidates := lo.Map(hits, func(hit search.IssueResult, _ int) string { return hit.ID })
+
+	for i, hit := range hits {
+		marker := " "
+		if lo.Contains(c.Matches, hit.ID) {
+			marker = "x"
+		}
+
+		fmt.Fprintf(out, "%3d. [%s] %-12s %6.3f  %s\n", i+1, marker, hit.ID, hit.Score, hit.Source.Title)
+	}
+
+	// Matches the search does not find anymore must stay visible, otherwise they are dropped silently.
+	for _, match := range c.Matches {
+		if !lo.Contains(candidates, match) {
+			fmt.Fprintf(out, "     [x] %-12s not in the top %d\n", match, len(hits))
+		}
+	}
+
+	reader := bufio.NewReader(in)
+
+	for {
+		fmt.Fprintf(out, "\nDuplicates (numbers or ids separated by commas, empty keeps [x]): ")
+
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, fmt.Errorf("no answer: %w", err)
+		}
+
+		if strings.TrimSpace(line) == "" {
+			if len(c.Matches) == 0 {
+				fmt.Fprintf(out, "Select at least one duplicate.\n")
+				continue
+			}
+
+			return c.Matches, nil
+		}
+
+		selected, err := acceptance.SelectMatches(line, candidates)
+		if err == nil && len(selected) > 0 {
+			return selected, nil
+		}
+
+		if err != nil {
+			fmt.Fprintf(out, "%s\n", err)
+		}
+	}
+}
+
+var datasetImportGithubCommand = &cobra.Command{
+	Use:   "import-github",
+	Short: "Add GitHub issues closed as duplicate and Jira issues with a duplicates link to the dataset",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := cmd.Context().Value(ConfigKey{}).(config.Config)
+
+		if lo.Contains(datasetOptions.sources, "jira") {
+			if err := acceptance.CheckJiraCredentials(cfg); err != nil {
+				return err
+			}
+		}
+
+		// Whatever was harvested before a source failed is imported anyway, the harvest can take a long time.
+		duplicates, harvestErr := harvestDuplicates(cfg, cmd)
+
+		created, extended, err := acceptance.ImportDuplicates(datasetOptions.dir, duplicates)
+		fmt.Fprintf(cmd.OutOrStdout(), "Created %d and extended %d cases in %s\n", created, extended, datasetOptions.dir)
+
+		if harvestErr != nil {
+			return harvestErr
+		}
+
+		return err
+	},
+}
+
+func harvestDuplicates(cfg config.Config, cmd *cobra.Command) ([]acceptance.Duplicate, error) {
+	var duplicates []acceptance.Duplicate
+
+	if lo.Contains(datasetOptions.sources, "github") {
+		var since time.Time
+		if datasetOptions.since > 0 {
+			since = time.Now().Add(-datasetOptions.since)
+		}
+
+		found, err := acceptance.GithubDuplicates(since, cfg, cmd.Context())
+		if err != nil {
+			return duplicates, err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Found %d GitHub issues closed as duplicate\n", len(found))
+		duplicates = append(duplicates, found...)
+	}
+
+	if lo.Contains(datasetOptions.sources, "jira") {
+		found, err := acceptance.JiraDuplicates(datasetOptions.jql, cfg, cmd.Context())
+		if err != nil {
+			return duplicates, err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Found %d Jira issues with a duplicates link\n", len(found))
+		duplicates = append(duplicates, found...)
+	}
+
+	return duplicates, nil
+}
+
+func registerDatasetFlags() {
+	datasetCommand.PersistentFlags().StringVar(&datasetOptions.dir, "dir", "duplicates", "directory of the dataset")
+
+	datasetAddCommand.Flags().IntVar(&datasetOptions.size, "size", 10, "number of top results to label")
+	datasetAddCommand.Flags().StringArrayVar(&datasetOptions.matches, "match", nil, "label this id as duplicate without asking, repeat for several")
+
+	flags := datasetImportGithubCommand.Flags()
+	flags.DurationVar(&datasetOptions.since, "since", 0, "only harvest issues updated within this duration, e.g. 720h, 0 harvests all")
+	flags.StringVar(&datasetOptions.jql, "jql", "issueLinkType = duplicates", "JQL selecting the Jira issues to harvest")
+	flags.StringSliceVar(&datasetOptions.sources, "source", []string{"github", "jira"}, "sources to harvest, github and/or jira")
+
+	datasetCommand.AddCommand(datasetAddCommand)
+	datasetCommand.AddCommand(datasetImportGithubCommand)
+}
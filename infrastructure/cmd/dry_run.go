@@ -1,104 +1,60 @@
 package cmd
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"os"
-	"strings"
-	"sync"
 
-	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
-	"github.com/shopwarelabs/jira-issue-bot/domain/search"
+	"github.com/shopwarelabs/jira-issue-bot/domain/dry_run"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
-	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
 	"github.com/spf13/cobra"
 )
 
+var dryRunOptions struct {
+	dry_run.Options
+	output string
+	format string
+}
+
 var dryRunCommand = &cobra.Command{
 	Use:   "dry-run",
-	Short: "Perform a dry-run on the existing issues and find duplicates for those.",
+	Short: "Perform a dry-run on the existing issues and report candidate duplicate pairs.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := cmd.Context().Value(ConfigKey{}).(config.Config)
-		logger := logging.FromContext(cmd.Context())
 
-		req := opensearchapi.SearchRequest{
-			Index: []string{"issues"},
-			Body: strings.NewReader(`{
-				"size": 1000,
-				"query": {
-					"bool": {
-						"must": [
-							{										
-								"term": {
-									"status.keyword": "open"		
-								}
-							},
-							{
-								"term": {	
-									"source.keyword": "github"	
-								}
-							}
-						]
-					}
-				}
-			}`),
+		format := dryRunOptions.format
+		if format == "" {
+			format = dry_run.FormatOf(dryRunOptions.output)
 		}
 
-		resp, err := req.Do(context.Background(), cfg.OpensearchClient)
+		report, err := dry_run.Run(dryRunOptions.Options, cfg, cmd.Context())
 		if err != nil {
-			return fmt.Errorf("failed to execute search: %w", err)
+			return err
 		}
 
-		var result search.SearchResponse
-
-		body, _ := io.ReadAll(resp.Body)
-		defer resp.Body.Close()
-
-		if err := json.Unmarshal(body, &result); err != nil {
-			return fmt.Errorf("failed to decode opensearch response: %w", err)
+		if dryRunOptions.output == "" {
+			return dry_run.Write(cmd.OutOrStdout(), format, report)
 		}
 
-		var wg sync.WaitGroup
-		guard := make(chan struct{}, 10)
-
-		for _, issue := range result.Hits.Hits {
-			wg.Add(1)
-			guard <- struct{}{}
-
-			go func(issue search.IssueResult) {
-				var matches []string
-
-				result, err := search.Search(
-					issue.Source.Title,
-					issue.Source.Title,
-					search.SearchFilter{ExcludedDocumentId: issue.ID},
-					cfg)
-				if err != nil {
-					logger.Error("search failed", err)
-					os.Exit(1)
-				}
-
-				for _, hit := range result.Hits.Hits {
-					if hit.Score < 2.0 {
-						continue
-					}
-
-					matches = append(matches, hit.ID+" (Score:"+fmt.Sprint(hit.Score)+")")
-				}
-
-				if len(matches) > 0 {
-					logger.Error("Issue \"%s\" might be duplictated by: \"%s\"\n", issue.ID, strings.Join(matches, ", "))
-				}
-
-				<-guard
-				defer wg.Done()
-			}(issue)
+		if err := writeReport(dryRunOptions.output, func(file *os.File) error {
+			return dry_run.Write(file, format, report)
+		}); err != nil {
+			return err
 		}
 
-		wg.Wait()
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %d candidate pairs among %d documents to %s\n", len(report.Pairs), report.Documents, dryRunOptions.output)
 
 		return nil
 	},
 }
+
+func registerDryRunFlags() {
+	defaults := dry_run.DefaultOptions()
+
+	flags := dryRunCommand.Flags()
+	flags.StringSliceVar(&dryRunOptions.Sources, "source", defaults.Sources, "sources of the checked documents, empty checks all")
+	flags.StringVar(&dryRunOptions.Status, "status", defaults.Status, "status of the checked documents, empty checks all")
+	flags.Float64Var(&dryRunOptions.MinScore, "min-score", defaults.MinScore, "minimum score of a candidate duplicate")
+	flags.IntVar(&dryRunOptions.Size, "size", defaults.Size, "maximum number of candidates per document")
+	flags.StringVarP(&dryRunOptions.output, "output", "o", "", "write the report to this file instead of stdout")
+	flags.StringVar(&dryRunOptions.format, "format", "", "report format csv, json or html, defaults to the extension of --output or csv")
+}
@@ -19,7 +19,7 @@ var initOpensearchCommand = &cobra.Command{
 
 		logger.Info("Creating model")
 
-		modelId := open_search.CreateModel(cfg, ctx, logger)
+		modelId := open_search.CreateModel(cfg, ctx)
 		cfg.ModelId = modelId
 
 		logger.Info("Model created")
@@ -29,17 +29,17 @@ var initOpensearchCommand = &cobra.Command{
 
 		logger.Info("Loading model")
 
-		if err := open_search.LoadModel(cfg, ctx, logger); err != nil {
+		if err := open_search.LoadModel(cfg, ctx); err != nil {
 			return err
 		}
 
 		logger.Info("Model loaded")
 
-		open_search.CreatePipeline(cfg, ctx, logger)
+		open_search.CreatePipeline(cfg, ctx)
 
 		logger.Info("Pipeline created")
 
-		open_search.CreateIndex(cfg, ctx, logger)
+		open_search.CreateIndex(cfg, ctx)
 
 		logger.Info("Index created")
 
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
-	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/open_search"
 	"github.com/spf13/cobra"
 )
@@ -13,8 +12,7 @@ var loadModelCommand = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		cfg := ctx.Value(ConfigKey{}).(config.Config)
-		logger := logging.FromContext(ctx)
 
-		return open_search.LoadModel(cfg, ctx, logger)
+		return open_search.LoadModel(cfg, ctx)
 	},
 }
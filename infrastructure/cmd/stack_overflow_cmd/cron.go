@@ -1,7 +1,7 @@
 package stack_overflow_cmd
 
 import (
-	"github.com/shopwarelabs/jira-issue-bot/domain/stack_overflow_connector"
+	"github.com/shopwarelabs/jira-issue-bot/domain/connector"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/cmd"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
@@ -9,32 +9,26 @@ import (
 )
 
 var cronCommand = &cobra.Command{
-	Use:   "stack-overflow-cron",
-	Short: "Reindex the latest stack overflow questions",
+	Use:        "stack-overflow-cron",
+	Short:      "Reindex the latest stack overflow questions",
+	Deprecated: "the server syncs Stack Overflow itself, use \"sync stack-overflow\" for manual runs",
 	RunE: func(command *cobra.Command, args []string) error {
 		ctx := command.Context()
 		cfg := ctx.Value(cmd.ConfigKey{}).(config.Config)
-		logger := logging.FromContext(ctx)
 
-		// Fetch only the first page, should be enough as we execute this every hour
-		questions, err := stack_overflow_connector.GetQuestions(1, "activity", "shopware6", ctx)
+		source, err := connector.Get("stack-overflow")
 		if err != nil {
 			return err
 		}
 
-		guard := make(chan struct{}, 14)
+		// Same as sync stack-overflow, which pages until all questions with activity since the last run are indexed.
+		result, err := connector.SyncFromCheckpoint(source, cfg, ctx)
+		logging.FromContext(ctx).Infof("Indexed %d items, %d failed", result.Indexed, result.Failed)
 
-		for _, question := range questions.Items {
-			guard <- struct{}{}
-
-			go func(question stack_overflow_connector.StackoverflowListingElement) {
-				if err := stack_overflow_connector.IndexSingleStackOverflowQuestion(&question, cfg, logger); err != nil {
-					logger.Error(err)
-				}
-				<-guard
-			}(question)
-		}
-
-		return nil
+		return err
 	},
 }
+
+func Register(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(cronCommand)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/acceptance"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/spf13/cobra"
+)
+
+var tuneOptions struct {
+	dir        string
+	size       int
+	profile    string
+	jsonReport string
+	step       float64
+}
+
+var tuneCommand = &cobra.Command{
+	Use:   "tune",
+	Short: "Find the score thresholds that separate duplicates from other hits in the labelled dataset",
+	Long: `Find the score thresholds that separate duplicates from other hits in the labelled dataset.
+
+The thresholds are only printed, apply them with GITHUB_COMMENT_MIN_SCORE and "/issues config min_score=...".
+They only apply to the bot when the profile uses its index, model and ranking.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := cmd.Context().Value(ConfigKey{}).(config.Config)
+
+		if tuneOptions.size <= 0 {
+			return fmt.Errorf("size must be positive")
+		}
+
+		profile := acceptance.Profile{}
+		if tuneOptions.profile != "" {
+			var err error
+			if profile, err = acceptance.LoadProfile(tuneOptions.profile); err != nil {
+				return err
+			}
+		}
+
+		cases, err := acceptance.LoadDataset(tuneOptions.dir)
+		if err != nil {
+			return err
+		}
+
+		hits, errors := acceptance.CollectScores(cases, acceptance.ScoreSearch(profile, tuneOptions.size, cfg), cmd.Context())
+
+		report := acceptance.Tune(hits)
+		report.Profile = profile.Name
+		report.Cases = len(cases)
+		report.Errors = errors
+
+		out := cmd.OutOrStdout()
+
+		fmt.Fprintf(out, "Collected %d hits of %d cases, %d searches failed\n\n", len(hits), len(cases), errors)
+
+		if err := acceptance.WriteTuneSummary(out, report, tuneOptions.step); err != nil {
+			return err
+		}
+
+		if tuneOptions.jsonReport != "" {
+			if err := writeReport(tuneOptions.jsonReport, func(file *os.File) error {
+				return acceptance.WriteJSON(file, report)
+			}); err != nil {
+				return err
+			}
+		}
+
+		if !profile.MatchesBot(cfg) {
+			fmt.Fprintf(out, "\nThe thresholds do not apply to the bot, profile %s changes the index, model or ranking\n", profile.Name)
+
+			return nil
+		}
+
+		// The bot reads the thresholds from its environment and the channel configs, not from a ranking profile.
+		if comment, err := report.UseCase("github-comment"); err == nil && comment.Optimal.Hits > 0 {
+			fmt.Fprintf(out, "\nApply to GitHub comments with GITHUB_COMMENT_MIN_SCORE=%.3f\n", comment.Optimal.Threshold)
+		}
+
+		if answer, err := report.UseCase("slack-answer"); err == nil && answer.Optimal.Hits > 0 {
+			fmt.Fprintf(out, "Apply to a Slack channel with /issues config min_score=%.3f\n", answer.Optimal.Threshold)
+		}
+
+		return nil
+	},
+}
+
+func registerTuneFlags() {
+	flags := tuneCommand.Flags()
+	flags.StringVar(&tuneOptions.dir, "dir", "duplicates", "directory of the dataset, one <issue number>.json per GitHub issue")
+	flags.IntVar(&tuneOptions.size, "size", 20, "number of hits to collect per issue")
+	flags.StringVar(&tuneOptions.profile, "profile", "", "search with this YAML ranking profile")
+	flags.StringVar(&tuneOptions.jsonReport, "json", "", "write the curves as JSON to this file")
+	flags.Float64Var(&tuneOptions.step, "step", 0.1, "score step of the printed curve, 0 prints every point")
+}
@@ -8,11 +8,14 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/caarlos0/env/v6"
 	"github.com/google/go-github/v50/github"
 	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/tracing"
 )
 
 type Config struct {
@@ -20,17 +23,69 @@ type Config struct {
 	OpensearchUrl string `env:"OPEN_SEARCH_URL"`
 	ModelName     string `env:"MODEL_NAME"`
 	IndexName     string `env:"INDEX_NAME"`
+	ModelAutoLoad bool   `env:"MODEL_AUTO_LOAD" envDefault:"false"`
 
 	GitHubAppId          int64  `env:"GITHUB_APP_ID"`
 	GithubInstallationId int64  `env:"GITHUB_INSTALLATION_ID"`
 	GITHUB_PRIVATE_KEY   string `env:"GITHUB_PRIVATE_KEY"`
 	GithubWebhookSecret  string `env:"GITHUB_WEBHOOK_SECRET"`
+	// GithubCommentMinScore is the score a hit needs to be mentioned in a comment on a new issue, see the tune command.
+	GithubCommentMinScore float64 `env:"GITHUB_COMMENT_MIN_SCORE"`
+	// GithubLabelMinScore is the score of the top hit above which a new issue is labelled as possible duplicate, zero disables labelling.
+	GithubLabelMinScore  float64 `env:"GITHUB_LABEL_MIN_SCORE"`
+	GithubDuplicateLabel string  `env:"GITHUB_DUPLICATE_LABEL" envDefault:"possible-duplicate"`
+	// GithubActionsDryRun only logs the comments and labels for new issues instead of creating them.
+	GithubActionsDryRun bool `env:"GITHUB_ACTIONS_DRY_RUN" envDefault:"false"`
+	// GithubDiscussionAnswer answers new discussions with similar resolved issues and answered discussions.
+	GithubDiscussionAnswer bool `env:"GITHUB_DISCUSSION_ANSWER" envDefault:"false"`
 
 	JiraToken string `env:"JIRA_TOKEN"`
 	JiraEmail string `env:"JIRA_EMAIL"`
-
-	SlackSigningSecret string `env:"SLACK_SIGNING_SECRET"`
-	SlackBotToken      string `env:"SLACK_BOT_TOKEN"`
+	JiraUrl   string `env:"JIRA_URL" envDefault:"https://shopware.atlassian.net"`
+
+	DiscourseUrl           string `env:"DISCOURSE_URL"`
+	DiscourseWebhookSecret string `env:"DISCOURSE_WEBHOOK_SECRET"`
+
+	SlackSigningSecret   string        `env:"SLACK_SIGNING_SECRET"`
+	SlackBotToken        string        `env:"SLACK_BOT_TOKEN"`
+	SlackAppToken        string        `env:"SLACK_APP_TOKEN"`
+	SlackSocketMode      bool          `env:"SLACK_SOCKET_MODE" envDefault:"false"`
+	SlackChannelConfig   string        `env:"SLACK_CHANNEL_CONFIG"`
+	SlackAdminUsers      []string      `env:"SLACK_ADMIN_USERS" envSeparator:","`
+	SlackMonitorInterval time.Duration `env:"SLACK_MONITOR_INTERVAL" envDefault:"10m"`
+	SlackDismissReaction string        `env:"SLACK_DISMISS_REACTION" envDefault:"x"`
+
+	ServerAddr            string        `env:"SERVER_ADDR" envDefault:":8000"`
+	ServerTLSCertFile     string        `env:"SERVER_TLS_CERT_FILE"`
+	ServerTLSKeyFile      string        `env:"SERVER_TLS_KEY_FILE"`
+	ServerReadTimeout     time.Duration `env:"SERVER_READ_TIMEOUT" envDefault:"30s"`
+	ServerWriteTimeout    time.Duration `env:"SERVER_WRITE_TIMEOUT" envDefault:"30s"`
+	ServerShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" envDefault:"30s"`
+
+	ApiKeys []string `env:"API_KEYS" envSeparator:","`
+	// AdminApiKeys grant access to the admin endpoints, which are disabled while it is empty.
+	AdminApiKeys []string `env:"ADMIN_API_KEYS" envSeparator:","`
+
+	// Jobs which run inside the server, a zero interval disables a job.
+	SchedulerEnabled              bool          `env:"SCHEDULER_ENABLED" envDefault:"false"`
+	SchedulerJitter               time.Duration `env:"SCHEDULER_JITTER" envDefault:"1m"`
+	SchedulerLockTTL              time.Duration `env:"SCHEDULER_LOCK_TTL" envDefault:"2m"`
+	StackOverflowSyncInterval     time.Duration `env:"STACK_OVERFLOW_SYNC_INTERVAL" envDefault:"1h"`
+	GithubSyncInterval            time.Duration `env:"GITHUB_SYNC_INTERVAL" envDefault:"6h"`
+	GithubDiscussionsSyncInterval time.Duration `env:"GITHUB_DISCUSSIONS_SYNC_INTERVAL" envDefault:"0"`
+	DiscourseSyncInterval         time.Duration `env:"DISCOURSE_SYNC_INTERVAL" envDefault:"6h"`
+	ModelHealthInterval           time.Duration `env:"MODEL_HEALTH_INTERVAL" envDefault:"5m"`
+	DryRunReportInterval          time.Duration `env:"DRY_RUN_REPORT_INTERVAL" envDefault:"0"`
+
+	TracesExporter string `env:"OTEL_TRACES_EXPORTER" envDefault:"none"`
+
+	// Timeouts of single operations, a zero timeout disables it.
+	SearchTimeout    time.Duration `env:"SEARCH_TIMEOUT" envDefault:"10s"`
+	IndexTimeout     time.Duration `env:"INDEX_TIMEOUT" envDefault:"10s"`
+	GithubTimeout    time.Duration `env:"GITHUB_TIMEOUT" envDefault:"15s"`
+	JiraTimeout      time.Duration `env:"JIRA_TIMEOUT" envDefault:"15s"`
+	DiscourseTimeout time.Duration `env:"DISCOURSE_TIMEOUT" envDefault:"15s"`
+	SlackTimeout     time.Duration `env:"SLACK_TIMEOUT" envDefault:"10s"`
 
 	ModelId          string
 	OpensearchClient *opensearch.Client
@@ -51,6 +106,7 @@ func NewFromEnv(ctx context.Context) (Config, error) {
 
 	cfg.OpensearchClient, _ = opensearch.NewClient(opensearch.Config{
 		Addresses: []string{cfg.OpensearchUrl},
+		Transport: tracing.Transport(http.DefaultTransport),
 	})
 
 	decodedPrivateKey, err := base64.StdEncoding.DecodeString(cfg.GITHUB_PRIVATE_KEY)
@@ -63,14 +119,23 @@ func NewFromEnv(ctx context.Context) (Config, error) {
 
 	if err != nil {
 		log.Printf("Error creating GitHub client: %v, using default client", err)
-		cfg.GithubClient = github.NewClient(nil)
+		cfg.GithubClient = github.NewClient(metrics.HTTPClient("github"))
 	} else {
-		cfg.GithubClient = github.NewClient(&http.Client{Transport: itr})
+		cfg.GithubClient = github.NewClient(&http.Client{Transport: metrics.Transport("github", tracing.Transport(itr))})
 	}
 
 	return cfg, nil
 }
 
+// WithTimeout limits ctx to one of the configured operation timeouts, a zero timeout only keeps the deadline of ctx.
+func (cfg Config) WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
 func findModel(config Config, ctx context.Context) (string, bool) {
 	var searchForModelRequest = []byte(`{
 	  "query": {
@@ -0,0 +1,160 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/open_search"
+	"go.uber.org/zap"
+)
+
+type Check struct {
+	Name    string `json:"name"`
+	Ok      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+type Report struct {
+	Ready  bool    `json:"ready"`
+	Checks []Check `json:"checks"`
+}
+
+// Checker checks whether everything a search needs is available. If MODEL_AUTO_LOAD is enabled,
+// it loads the model again when it finds it undeployed, e.g. after OpenSearch was restarted.
+type Checker struct {
+	config  config.Config
+	logger  *zap.SugaredLogger
+	loading atomic.Bool
+}
+
+func NewChecker(config config.Config, logger *zap.SugaredLogger) *Checker {
+	return &Checker{config: config, logger: logger}
+}
+
+func (c *Checker) Readiness(ctx context.Context) Report {
+	report := Report{Ready: true}
+
+	add := func(check Check) bool {
+		report.Checks = append(report.Checks, check)
+		report.Ready = report.Ready && check.Ok
+
+		return check.Ok
+	}
+
+	if !add(c.checkOpensearch(ctx)) {
+		return report
+	}
+
+	add(c.checkIndex(ctx))
+	add(c.checkModel(ctx))
+
+	return report
+}
+
+func (c *Checker) checkOpensearch(ctx context.Context) Check {
+	check := Check{Name: "opensearch"}
+
+	resp, err := opensearchapi.PingRequest{}.Do(ctx, c.config.OpensearchClient)
+	if err != nil {
+		check.Message = err.Error()
+		return check
+	}
+
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		check.Message = resp.Status()
+		return check
+	}
+
+	check.Ok = true
+
+	return check
+}
+
+func (c *Checker) checkIndex(ctx context.Context) Check {
+	check := Check{Name: "index"}
+
+	resp, err := opensearchapi.IndicesExistsRequest{Index: []string{c.config.IndexName}}.Do(ctx, c.config.OpensearchClient)
+	if err != nil {
+		check.Message = err.Error()
+		return check
+	}
+
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		check.Message = fmt.Sprintf("index \"%s\" does not exist", c.config.IndexName)
+		return check
+	}
+
+	check.Ok = true
+
+	return check
+}
+
+func (c *Checker) checkModel(ctx context.Context) Check {
+	check := Check{Name: "model"}
+
+	// The model id is only looked up at startup, searches cannot use a model registered afterwards.
+	modelId := c.config.ModelId
+	if modelId == "" {
+		check.Message = fmt.Sprintf("model \"%s\" was not found at startup", c.config.ModelName)
+		return check
+	}
+
+	state, err := open_search.GetModelState(c.config, ctx, modelId)
+	if err != nil {
+		check.Message = err.Error()
+		return check
+	}
+
+	if !open_search.IsModelDeployed(state) {
+		check.Message = fmt.Sprintf("model \"%s\" is %s", modelId, state)
+
+		if c.config.ModelAutoLoad {
+			c.loadModel(modelId)
+			check.Message += ", loading it"
+		}
+
+		return check
+	}
+
+	check.Ok = true
+
+	return check
+}
+
+// loadModel loads the model in the background, it does nothing while a load is already running.
+func (c *Checker) loadModel(modelId string) {
+	if !c.loading.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer c.loading.Store(false)
+
+		// LoadModel panics when the load fails, which must not take down the server.
+		defer func() {
+			if r := recover(); r != nil {
+				c.logger.Errorf("Loading model \"%s\" failed: %v", modelId, r)
+			}
+		}()
+
+		cfg := c.config
+		cfg.ModelId = modelId
+
+		c.logger.Infof("Model \"%s\" is not deployed, loading it", modelId)
+
+		if err := open_search.LoadModel(cfg, logging.WithLogger(context.Background(), c.logger)); err != nil {
+			c.logger.Errorf("Loading model \"%s\" failed: %s", modelId, err)
+			return
+		}
+
+		c.logger.Infof("Model \"%s\" loaded", modelId)
+	}()
+}
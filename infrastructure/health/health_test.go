@@ -0,0 +1,19 @@
+package health
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+
+	"go.uber.org/zap"
+)
+
+func TestCheckModelWithoutModelIdIsNotReady(t *testing.T) {
+	checker := NewChecker(config.Config{ModelName: "all-MiniLM-L6-v2"}, zap.NewNop().Sugar())
+
+	// Searches use the id resolved at startup, a model found later would still not be used.
+	if check := checker.checkModel(context.Background()); check.Ok || check.Message != `model "all-MiniLM-L6-v2" was not found at startup` {
+		t.Errorf("expected the model check to fail, got %+v", check)
+	}
+}
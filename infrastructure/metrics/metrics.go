@@ -0,0 +1,171 @@
+// Package metrics holds the Prometheus collectors of the bot, they are served on /metrics.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/tracing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "issue_bot"
+
+var (
+	WebhookEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_events_total",
+		Help:      "Received GitHub and Slack events by source, event type and outcome.",
+	}, []string{"source", "event", "outcome"})
+
+	SearchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "search_duration_seconds",
+		Help:      "Duration of neural searches against OpenSearch.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10},
+	}, []string{"outcome"})
+
+	SearchHits = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "search_hits",
+		Help:      "Number of hits above the minimum score returned by a search.",
+		Buckets:   []float64{0, 1, 2, 3, 5, 10, 25, 50, 100},
+	})
+
+	IndexedDocuments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "indexed_documents_total",
+		Help:      "Documents written to the index by source and outcome.",
+	}, []string{"source", "outcome"})
+
+	OutboundRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbound_requests_total",
+		Help:      "Requests to GitHub, Slack and StackExchange by service and status class.",
+	}, []string{"service", "status"})
+
+	OutboundRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "outbound_request_duration_seconds",
+		Help:      "Duration of requests to GitHub, Slack and StackExchange.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service"})
+
+	JobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Runs of scheduled jobs by job and outcome.",
+	}, []string{"job", "outcome"})
+
+	JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Duration of scheduled jobs.",
+		Buckets:   []float64{1, 5, 15, 60, 300, 900, 3600},
+	}, []string{"job"})
+
+	GithubActions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "github_actions_total",
+		Help:      "Actions taken on new GitHub issues by action, whether it was a dry run and outcome.",
+	}, []string{"action", "dry_run", "outcome"})
+)
+
+// Outcome is the value of the outcome label for an operation which returned err.
+func Outcome(err error) string {
+	if err != nil {
+		return "error"
+	}
+
+	return "success"
+}
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// OtherWebhookEvent labels webhook requests which were not validated or whose event type is not handled, so the
+// sender can not create new series.
+const OtherWebhookEvent = "other"
+
+type webhookValidatedKey struct{}
+
+// WebhookValidated marks the webhook request of ctx as authentic, handlers call it once the signature is checked.
+func WebhookValidated(ctx context.Context) {
+	if validated, ok := ctx.Value(webhookValidatedKey{}).(*bool); ok {
+		*validated = true
+	}
+}
+
+// InstrumentWebhook counts the requests handled by next, the outcome is derived from the response status. The
+// event label is only taken from requests next marked with WebhookValidated.
+func InstrumentWebhook(source string, event func(r *http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		validated := false
+		r = r.WithContext(context.WithValue(r.Context(), webhookValidatedKey{}, &validated))
+
+		next.ServeHTTP(recorder, r)
+
+		outcome := "success"
+		if recorder.status >= http.StatusInternalServerError {
+			outcome = "error"
+		} else if recorder.status >= http.StatusBadRequest {
+			outcome = "rejected"
+		}
+
+		label := OtherWebhookEvent
+		if validated {
+			label = event(r)
+		}
+
+		WebhookEvents.WithLabelValues(source, label, outcome).Inc()
+	})
+}
+
+// HTTPClient returns a client which records and traces every request to the given service.
+func HTTPClient(service string) *http.Client {
+	return &http.Client{Transport: Transport(service, tracing.Transport(http.DefaultTransport))}
+}
+
+// Transport records every request to the given service sent through next.
+func Transport(service string, next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		start := time.Now()
+
+		response, err := next.RoundTrip(request)
+
+		OutboundRequestDuration.WithLabelValues(service).Observe(time.Since(start).Seconds())
+
+		status := "error"
+		if err == nil {
+			status = fmt.Sprintf("%dxx", response.StatusCode/100)
+		}
+
+		OutboundRequests.WithLabelValues(service, status).Inc()
+
+		return response, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
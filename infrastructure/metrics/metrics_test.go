@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentWebhookOnlyLabelsValidatedEvents(t *testing.T) {
+	handler := InstrumentWebhook("test", func(r *http.Request) string {
+		return r.Header.Get("X-Event")
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signature") != "valid" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		WebhookValidated(r.Context())
+	}))
+
+	send := func(event string, signature string) {
+		request := httptest.NewRequest(http.MethodPost, "/webhook/test", nil)
+		request.Header.Set("X-Event", event)
+		request.Header.Set("X-Signature", signature)
+
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	send("made-up", "invalid")
+	send("issues", "valid")
+
+	if count := testutil.ToFloat64(WebhookEvents.WithLabelValues("test", "made-up", "rejected")); count != 0 {
+		t.Errorf("expected no series for the event of an invalid request, got %f", count)
+	}
+
+	if count := testutil.ToFloat64(WebhookEvents.WithLabelValues("test", OtherWebhookEvent, "rejected")); count != 1 {
+		t.Errorf("expected the invalid request to be counted as %s, got %f", OtherWebhookEvent, count)
+	}
+
+	if count := testutil.ToFloat64(WebhookEvents.WithLabelValues("test", "issues", "success")); count != 1 {
+		t.Errorf("expected the valid request to be counted with its event, got %f", count)
+	}
+}
@@ -10,11 +10,12 @@ import (
 	"time"
 
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
-
-	"go.uber.org/zap"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
 )
 
-func LoadModel(config config.Config, ctx context.Context, logger *zap.SugaredLogger) error {
+func LoadModel(config config.Config, ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+
 	request, _ := http.NewRequestWithContext(ctx, "POST", config.OpensearchUrl+"/_plugins/_ml/models/"+config.ModelId+"/_load", nil)
 	request.Header.Set("Content-Type", "application/json; charset=UTF-8")
 
@@ -45,7 +46,9 @@ func LoadModel(config config.Config, ctx context.Context, logger *zap.SugaredLog
 	return nil
 }
 
-func CreatePipeline(config config.Config, ctx context.Context, logger *zap.SugaredLogger) {
+func CreatePipeline(config config.Config, ctx context.Context) {
+	logger := logging.FromContext(ctx)
+
 	var jsonData = []byte(`{
 	  "description": "Jira NLP pipeline",
 	  "processors" : [
@@ -69,7 +72,9 @@ func CreatePipeline(config config.Config, ctx context.Context, logger *zap.Sugar
 	logger.Debug("Pipeline created")
 }
 
-func CreateIndex(config config.Config, ctx context.Context, logger *zap.SugaredLogger) {
+func CreateIndex(config config.Config, ctx context.Context) {
+	logger := logging.FromContext(ctx)
+
 	var jsonData = []byte(`{
 		"settings": {
 		"index.knn": true,
@@ -115,7 +120,7 @@ func CreateIndex(config config.Config, ctx context.Context, logger *zap.SugaredL
 	logger.Debugf("Index \"%s\" created", config.IndexName)
 }
 
-func CreateModel(config config.Config, ctx context.Context, logger *zap.SugaredLogger) string {
+func CreateModel(config config.Config, ctx context.Context) string {
 	var jsonData = []byte(`{
 			 "name": "` + config.ModelName + `",
   			 "version": "1.0.0",
@@ -140,10 +145,12 @@ func CreateModel(config config.Config, ctx context.Context, logger *zap.SugaredL
 		panic(err)
 	}
 
-	return getModelId(result.TaskId, config, ctx, logger)
+	return getModelId(result.TaskId, config, ctx)
 }
 
-func getModelId(taskId string, config config.Config, ctx context.Context, logger *zap.SugaredLogger) string {
+func getModelId(taskId string, config config.Config, ctx context.Context) string {
+	logger := logging.FromContext(ctx)
+
 	var url = config.OpensearchUrl + "/_plugins/_ml/tasks/" + taskId
 	request, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
 	request.Header.Set("Content-Type", "application/json; charset=UTF-8")
@@ -0,0 +1,166 @@
+package open_search
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// Lock is a lease stored as document in OpenSearch, so only one of several replicas holds it at a time.
+// Concurrent updates are detected with the sequence number of the document.
+type Lock struct {
+	config config.Config
+	name   string
+	owner  string
+}
+
+type lockDocument struct {
+	Owner string `json:"owner"`
+	// Expires is the end of the lease in unix milliseconds.
+	Expires int64 `json:"expires"`
+}
+
+func NewLock(config config.Config, name string) *Lock {
+	hostname, _ := os.Hostname()
+
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+
+	return &Lock{config: config, name: name, owner: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))}
+}
+
+// Acquire takes the lock or renews it for ttl, it returns false while another owner holds an unexpired lease.
+func (l *Lock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	current, seqNo, primaryTerm, err := l.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if current != nil && current.Owner != l.owner && current.Expires > time.Now().UnixMilli() {
+		return false, nil
+	}
+
+	jsonString, _ := json.Marshal(lockDocument{Owner: l.owner, Expires: time.Now().Add(ttl).UnixMilli()})
+
+	req := opensearchapi.IndexRequest{
+		Index:      lockIndex(l.config),
+		DocumentID: l.name,
+		Body:       bytes.NewReader(jsonString),
+	}
+
+	if current == nil {
+		req.OpType = "create"
+	} else {
+		req.IfSeqNo = &seqNo
+		req.IfPrimaryTerm = &primaryTerm
+	}
+
+	ctx, cancel := l.config.WithTimeout(ctx, l.config.IndexTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, l.config.OpensearchClient)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock \"%s\": %w", l.name, err)
+	}
+
+	defer resp.Body.Close()
+
+	// Another owner created or renewed the lock in the meantime.
+	if resp.StatusCode == http.StatusConflict {
+		return false, nil
+	}
+
+	if resp.IsError() {
+		body, _ := io.ReadAll(resp.Body)
+
+		return false, fmt.Errorf("failed to acquire lock \"%s\": %s", l.name, body)
+	}
+
+	return true, nil
+}
+
+// Release deletes the lock if it is still held by this owner, so another replica can take over right away.
+func (l *Lock) Release(ctx context.Context) error {
+	current, seqNo, primaryTerm, err := l.get(ctx)
+	if err != nil || current == nil || current.Owner != l.owner {
+		return err
+	}
+
+	req := opensearchapi.DeleteRequest{
+		Index:         lockIndex(l.config),
+		DocumentID:    l.name,
+		IfSeqNo:       &seqNo,
+		IfPrimaryTerm: &primaryTerm,
+	}
+
+	ctx, cancel := l.config.WithTimeout(ctx, l.config.IndexTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, l.config.OpensearchClient)
+	if err != nil {
+		return fmt.Errorf("failed to release lock \"%s\": %w", l.name, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.IsError() && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusConflict {
+		body, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("failed to release lock \"%s\": %s", l.name, body)
+	}
+
+	return nil
+}
+
+func (l *Lock) get(ctx context.Context) (*lockDocument, int, int, error) {
+	req := opensearchapi.GetRequest{
+		Index:      lockIndex(l.config),
+		DocumentID: l.name,
+	}
+
+	ctx, cancel := l.config.WithTimeout(ctx, l.config.SearchTimeout)
+	defer cancel()
+
+	resp, err := req.Do(ctx, l.config.OpensearchClient)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to fetch lock \"%s\": %w", l.name, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, 0, 0, nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.IsError() {
+		return nil, 0, 0, fmt.Errorf("failed to fetch lock \"%s\": %s", l.name, body)
+	}
+
+	var stored struct {
+		SeqNo       int          `json:"_seq_no"`
+		PrimaryTerm int          `json:"_primary_term"`
+		Source      lockDocument `json:"_source"`
+	}
+
+	if err := json.Unmarshal(body, &stored); err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to decode lock \"%s\": %w", l.name, err)
+	}
+
+	return &stored.Source, stored.SeqNo, stored.PrimaryTerm, nil
+}
+
+func lockIndex(config config.Config) string {
+	return config.IndexName + "-locks"
+}
@@ -0,0 +1,59 @@
+package open_search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+)
+
+// IsModelDeployed reports whether the model is ready to create embeddings, older OpenSearch versions call this "LOADED".
+func IsModelDeployed(state string) bool {
+	return state == "DEPLOYED" || state == "LOADED"
+}
+
+// GetModelState returns the state of the model, e.g. "DEPLOYED" or "UNDEPLOYED".
+func GetModelState(config config.Config, ctx context.Context, modelId string) (string, error) {
+	request, _ := http.NewRequestWithContext(ctx, "GET", config.OpensearchUrl+"/_plugins/_ml/models/"+modelId, nil)
+
+	body, err := doRequestWithError(request)
+	if err != nil {
+		return "", err
+	}
+
+	var result ModelResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode model response: %w", err)
+	}
+
+	return result.ModelState, nil
+}
+
+// doRequestWithError is like doRequest, but returns errors instead of panicking, so it can be used at runtime.
+func doRequestWithError(request *http.Request) ([]byte, error) {
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("opensearch responded with status %d: %s", response.StatusCode, body)
+	}
+
+	return body, nil
+}
+
+type ModelResponse struct {
+	Name       string `json:"name"`
+	ModelState string `json:"model_state"`
+}
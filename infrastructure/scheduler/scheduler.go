@@ -0,0 +1,346 @@
+// Package scheduler runs periodic jobs inside the server. With several replicas only the one
+// holding the leader lock runs jobs, the others keep checking and take over when it goes away.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrJobRunning = errors.New("job is already running")
+	ErrNotLeader  = errors.New("another replica runs the jobs")
+)
+
+// Job runs every Interval, a zero interval disables the job.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Locker is a lease shared by all replicas, see open_search.Lock.
+type Locker interface {
+	// Acquire takes or renews the lease for ttl, it returns false while another replica holds it.
+	Acquire(ctx context.Context, ttl time.Duration) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// Status is the last run of a job, times are unix timestamps.
+type Status struct {
+	Name         string  `json:"name"`
+	Interval     string  `json:"interval"`
+	Running      bool    `json:"running"`
+	Runs         int     `json:"runs"`
+	LastStart    int64   `json:"lastStart,omitempty"`
+	LastDuration float64 `json:"lastDuration,omitempty"`
+	LastError    string  `json:"lastError,omitempty"`
+	LastSuccess  int64   `json:"lastSuccess,omitempty"`
+	NextRun      int64   `json:"nextRun,omitempty"`
+}
+
+type Overview struct {
+	Leader bool     `json:"leader"`
+	Jobs   []Status `json:"jobs"`
+}
+
+type Scheduler struct {
+	jobs    []*job
+	locker  Locker
+	lockTTL time.Duration
+	jitter  time.Duration
+	leader  atomic.Bool
+	logger  *zap.SugaredLogger
+
+	// leaderCtx is set while this process holds the lease, it is cancelled when the lease is lost so the
+	// running jobs stop before another replica starts them.
+	leaderLock   sync.Mutex
+	leaderCtx    context.Context
+	leaderCancel context.CancelFunc
+}
+
+type job struct {
+	Job
+	running atomic.Bool
+	trigger chan struct{}
+	lock    sync.Mutex
+	status  Status
+}
+
+// New creates a scheduler for the enabled jobs. Every run is delayed by a random duration up to jitter,
+// so replicas and jobs with the same interval do not hit the APIs at the same time. Without a locker
+// this process always runs the jobs.
+func New(locker Locker, lockTTL time.Duration, jitter time.Duration, logger *zap.SugaredLogger, jobs ...Job) *Scheduler {
+	scheduler := &Scheduler{locker: locker, lockTTL: lockTTL, jitter: jitter, logger: logger}
+	scheduler.leader.Store(locker == nil)
+
+	for _, j := range jobs {
+		if j.Interval <= 0 {
+			continue
+		}
+
+		scheduler.jobs = append(scheduler.jobs, &job{
+			Job:     j,
+			trigger: make(chan struct{}, 1),
+			status:  Status{Name: j.Name, Interval: j.Interval.String()},
+		})
+	}
+
+	return scheduler
+}
+
+// Run blocks until ctx is cancelled and the running jobs returned.
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.jobs) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	// The lease is taken before the jobs start, otherwise the first runs of the leader would be skipped.
+	if s.locker == nil {
+		s.setLeader(ctx, true)
+	} else {
+		s.acquireLeadership(ctx)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			s.holdLeadership(ctx)
+		}()
+	}
+
+	for _, j := range s.jobs {
+		wg.Add(1)
+
+		go func(j *job) {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}(j)
+	}
+
+	wg.Wait()
+}
+
+// Trigger runs the job right away instead of waiting for the next interval.
+func (s *Scheduler) Trigger(name string) error {
+	for _, j := range s.jobs {
+		if j.Name != name {
+			continue
+		}
+
+		if !s.leader.Load() {
+			return ErrNotLeader
+		}
+
+		if j.running.Load() {
+			return ErrJobRunning
+		}
+
+		select {
+		case j.trigger <- struct{}{}:
+		default:
+		}
+
+		return nil
+	}
+
+	return ErrUnknownJob
+}
+
+func (s *Scheduler) Overview() Overview {
+	overview := Overview{Leader: s.leader.Load(), Jobs: []Status{}}
+
+	for _, j := range s.jobs {
+		j.lock.Lock()
+		status := j.status
+		j.lock.Unlock()
+
+		status.Running = j.running.Load()
+		overview.Jobs = append(overview.Jobs, status)
+	}
+
+	return overview
+}
+
+// holdLeadership renews the leader lock well before it expires and releases it on shutdown.
+func (s *Scheduler) holdLeadership(ctx context.Context) {
+	ticker := time.NewTicker(s.renewInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.setLeader(ctx, false)
+
+			releaseCtx, cancel := context.WithTimeout(logging.WithLogger(context.Background(), s.logger), 5*time.Second)
+			defer cancel()
+
+			if err := s.locker.Release(releaseCtx); err != nil {
+				s.logger.Errorf("Failed to release the scheduler lock: %s", err)
+			}
+
+			return
+		case <-ticker.C:
+		}
+
+		s.acquireLeadership(ctx)
+	}
+}
+
+// acquireLeadership takes or renews the lease, a failed renewal counts as lost lease.
+func (s *Scheduler) acquireLeadership(ctx context.Context) {
+	leader, err := s.locker.Acquire(ctx, s.lockTTL)
+	if err != nil {
+		s.logger.Errorf("Failed to acquire the scheduler lock: %s", err)
+	}
+
+	s.setLeader(ctx, leader)
+}
+
+func (s *Scheduler) setLeader(ctx context.Context, leader bool) {
+	s.leaderLock.Lock()
+	defer s.leaderLock.Unlock()
+
+	if leader && s.leaderCtx == nil {
+		s.leaderCtx, s.leaderCancel = context.WithCancel(ctx)
+	} else if !leader && s.leaderCtx != nil {
+		s.leaderCancel()
+		s.leaderCtx, s.leaderCancel = nil, nil
+	}
+
+	if leader != s.leader.Swap(leader) {
+		s.logger.Infof("Scheduler leadership changed, leader: %t", leader)
+	}
+}
+
+// leaderContext returns the context for job runs, it is nil while another replica is the leader.
+func (s *Scheduler) leaderContext() context.Context {
+	s.leaderLock.Lock()
+	defer s.leaderLock.Unlock()
+
+	return s.leaderCtx
+}
+
+// renewInterval is how often the lease is renewed, a follower checks as often whether it took over.
+func (s *Scheduler) renewInterval() time.Duration {
+	return s.lockTTL / 3
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	// The first run only waits for the jitter, so a restart does not postpone all jobs by a full interval.
+	delay := s.randomJitter()
+
+	for {
+		j.lock.Lock()
+		j.status.NextRun = time.Now().Add(delay).Unix()
+		j.lock.Unlock()
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-j.trigger:
+			timer.Stop()
+		}
+
+		// A follower retries soon, so it runs the job right away once it takes over.
+		leaderCtx := s.leaderContext()
+		if leaderCtx == nil {
+			s.logger.Debugf("Skipping job %s, another replica is the scheduler leader", j.Name)
+			delay = s.renewInterval() + s.randomJitter()
+			continue
+		}
+
+		s.run(leaderCtx, j)
+
+		delay = j.Interval + s.randomJitter()
+	}
+}
+
+// run runs the job with ctx, which is cancelled when the scheduler loses the lease.
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	if !j.running.CompareAndSwap(false, true) {
+		return
+	}
+
+	defer j.running.Store(false)
+
+	logger := s.logger.With("job", j.Name)
+	ctx = logging.WithLogger(ctx, logger)
+
+	ctx, span := tracing.Tracer().Start(ctx, "job "+j.Name, trace.WithAttributes(attribute.String("job.name", j.Name)))
+	defer span.End()
+
+	start := time.Now()
+
+	j.lock.Lock()
+	j.status.LastStart = start.Unix()
+	j.lock.Unlock()
+
+	logger.Infof("Running job %s", j.Name)
+
+	err := s.safeRun(ctx, j)
+	duration := time.Since(start)
+
+	metrics.JobRuns.WithLabelValues(j.Name, metrics.Outcome(err)).Inc()
+	metrics.JobDuration.WithLabelValues(j.Name).Observe(duration.Seconds())
+
+	j.lock.Lock()
+	j.status.Runs++
+	j.status.LastDuration = duration.Seconds()
+	j.status.LastError = ""
+	if err != nil {
+		j.status.LastError = err.Error()
+	} else {
+		j.status.LastSuccess = time.Now().Unix()
+	}
+	j.lock.Unlock()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Errorf("Job %s failed after %s: %s", j.Name, duration, err)
+		return
+	}
+
+	logger.Infof("Job %s finished after %s", j.Name, duration)
+}
+
+// safeRun turns a panic of the job into an error, so it does not take down the server.
+func (s *Scheduler) safeRun(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return j.Run(ctx)
+}
+
+func (s *Scheduler) randomJitter() time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(s.jitter)))
+}
@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type fakeLocker struct {
+	acquire  atomic.Bool
+	released atomic.Bool
+}
+
+func (l *fakeLocker) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	return l.acquire.Load(), nil
+}
+
+func (l *fakeLocker) Release(ctx context.Context) error {
+	l.released.Store(true)
+
+	return nil
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTriggerRunsJobWithoutOverlap(t *testing.T) {
+	release := make(chan struct{})
+	var runs atomic.Int32
+
+	scheduler := New(nil, 0, 0, zap.NewNop().Sugar(),
+		Job{Name: "slow", Interval: time.Hour, Run: func(ctx context.Context) error {
+			runs.Add(1)
+			<-release
+			return errors.New("failed")
+		}},
+		Job{Name: "disabled", Run: func(ctx context.Context) error { return nil }},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	// Without jitter the first run starts right away.
+	waitFor(t, func() bool { return runs.Load() == 1 })
+
+	if err := scheduler.Trigger("slow"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("expected the running job to be rejected, got %v", err)
+	}
+
+	if err := scheduler.Trigger("disabled"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("expected disabled jobs to be unknown, got %v", err)
+	}
+
+	close(release)
+	waitFor(t, func() bool { return !scheduler.Overview().Jobs[0].Running })
+
+	if err := scheduler.Trigger("slow"); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return scheduler.Overview().Jobs[0].Runs == 2 })
+
+	status := scheduler.Overview().Jobs[0]
+	if status.LastError != "failed" || status.LastSuccess != 0 || status.Name != "slow" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestOnlyLeaderRunsJobs(t *testing.T) {
+	locker := &fakeLocker{}
+	var runs atomic.Int32
+
+	scheduler := New(locker, time.Minute, 0, zap.NewNop().Sugar(), Job{Name: "job", Interval: time.Hour, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	if runs.Load() != 0 || scheduler.Overview().Leader {
+		t.Errorf("expected a follower to skip the job")
+	}
+
+	if err := scheduler.Trigger("job"); !errors.Is(err, ErrNotLeader) {
+		t.Errorf("expected trigger to be rejected on a follower, got %v", err)
+	}
+
+	cancel()
+	<-done
+
+	if !locker.released.Load() {
+		t.Error("expected the lock to be released on shutdown")
+	}
+}
+
+func TestFollowerRunsJobsAfterTakingOver(t *testing.T) {
+	locker := &fakeLocker{}
+	var runs atomic.Int32
+
+	scheduler := New(locker, 30*time.Millisecond, 0, zap.NewNop().Sugar(), Job{Name: "job", Interval: time.Hour, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go scheduler.Run(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+	locker.acquire.Store(true)
+
+	// The skipped run is retried with the lease renewal instead of waiting for the interval.
+	waitFor(t, func() bool { return runs.Load() == 1 })
+}
+
+func TestLosingLeadershipCancelsJobs(t *testing.T) {
+	locker := &fakeLocker{}
+	locker.acquire.Store(true)
+
+	started := make(chan struct{})
+	var cancelled atomic.Bool
+
+	scheduler := New(locker, 30*time.Millisecond, 0, zap.NewNop().Sugar(), Job{Name: "job", Interval: time.Hour, Run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		cancelled.Store(true)
+		return ctx.Err()
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go scheduler.Run(ctx)
+
+	// The lease is acquired before the loops start, so the leader runs the job right away.
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("expected the leader to run the job right away")
+	}
+
+	locker.acquire.Store(false)
+
+	waitFor(t, func() bool { return cancelled.Load() && !scheduler.Overview().Leader })
+}
+
+func TestPanickingJobIsRecorded(t *testing.T) {
+	scheduler := New(nil, 0, 0, zap.NewNop().Sugar(), Job{Name: "panic", Interval: time.Hour, Run: func(ctx context.Context) error {
+		panic("boom")
+	}})
+
+	scheduler.run(context.Background(), scheduler.jobs[0])
+
+	if status := scheduler.Overview().Jobs[0]; status.LastError != "job panicked: boom" || status.Runs != 1 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
@@ -0,0 +1,91 @@
+// Package tracing sets up OpenTelemetry, spans are exported as configured by OTEL_TRACES_EXPORTER.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const serviceName = "issue-bot"
+
+// Setup installs the global tracer provider, the returned function flushes the remaining spans.
+// With the "none" exporter spans are created but dropped, so the instrumentation can stay in place.
+func Setup(exporterName string, ctx context.Context) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch exporterName {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		// The endpoint is read from OTEL_EXPORTER_OTLP_ENDPOINT, e.g. a local collector.
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	default:
+		return nil, fmt.Errorf("unknown traces exporter \"%s\", use otlp, stdout or none", exporterName)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create traces exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer("github.com/shopwarelabs/jira-issue-bot")
+}
+
+// Transport creates a client span for every request sent through next.
+func Transport(next http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(next)
+}
+
+// Handler creates a server span for every request except probes and metric scrapes, named after the
+// pattern it was routed to. The request context carries a logger with the trace id, so log messages
+// can be matched with the trace.
+func Handler(mux *http.ServeMux, ctx context.Context) http.Handler {
+	logger := logging.FromContext(ctx)
+
+	withLogger := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestLogger := logger
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.HasTraceID() {
+			requestLogger = logger.With("trace_id", spanContext.TraceID().String())
+		}
+
+		mux.ServeHTTP(w, r.WithContext(logging.WithLogger(r.Context(), requestLogger)))
+	})
+
+	return otelhttp.NewHandler(withLogger, "http.request", otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+		_, pattern := mux.Handler(r)
+
+		return r.Method + " " + pattern
+	}), otelhttp.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/healthz" && r.URL.Path != "/readyz" && r.URL.Path != "/metrics"
+	}))
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/connector"
+	"github.com/shopwarelabs/jira-issue-bot/domain/dry_run"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/health"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/open_search"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/scheduler"
+
+	"go.uber.org/zap"
+)
+
+// newScheduler creates the periodic jobs of the server, it has no jobs if SCHEDULER_ENABLED is off.
+func newScheduler(cfg config.Config, logger *zap.SugaredLogger) *scheduler.Scheduler {
+	if !cfg.SchedulerEnabled {
+		return scheduler.New(nil, 0, 0, logger)
+	}
+
+	checker := health.NewChecker(cfg, logger)
+
+	jobs := []scheduler.Job{
+		{Name: "stack-overflow-sync", Interval: cfg.StackOverflowSyncInterval, Run: syncJob("stack-overflow", cfg)},
+		{Name: "github-sync", Interval: cfg.GithubSyncInterval, Run: syncJob("github", cfg)},
+		{Name: "github-discussions-sync", Interval: cfg.GithubDiscussionsSyncInterval, Run: syncJob("github-discussions", cfg)},
+		{Name: "model-health", Interval: cfg.ModelHealthInterval, Run: func(ctx context.Context) error {
+			report := checker.Readiness(ctx)
+			if report.Ready {
+				return nil
+			}
+
+			var failed []string
+			for _, check := range report.Checks {
+				if !check.Ok {
+					failed = append(failed, fmt.Sprintf("%s: %s", check.Name, check.Message))
+				}
+			}
+
+			return fmt.Errorf("not ready, %s", strings.Join(failed, ", "))
+		}},
+		{Name: "dry-run-report", Interval: cfg.DryRunReportInterval, Run: func(ctx context.Context) error {
+			logger := logging.FromContext(ctx)
+
+			report, err := dry_run.Run(dry_run.DefaultOptions(), cfg, ctx)
+			if err != nil {
+				return err
+			}
+
+			logger.Infof("Dry run found %d candidate duplicate pairs among %d documents, %d searches failed", len(report.Pairs), report.Documents, report.Errors)
+
+			for _, pair := range report.Pairs {
+				logger.Infow("Candidate duplicate", "document", pair.Document.Id, "match", pair.Match.Id, "score", pair.Score)
+			}
+
+			return nil
+		}},
+	}
+
+	// The forum is optional, without a DISCOURSE_URL its job could only fail.
+	if cfg.DiscourseUrl != "" {
+		jobs = append(jobs, scheduler.Job{Name: "discourse-sync", Interval: cfg.DiscourseSyncInterval, Run: syncJob("discourse", cfg)})
+	}
+
+	return scheduler.New(open_search.NewLock(cfg, "scheduler"), cfg.SchedulerLockTTL, cfg.SchedulerJitter, logger, jobs...)
+}
+
+func syncJob(source string, cfg config.Config) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		c, err := connector.Get(source)
+		if err != nil {
+			return err
+		}
+
+		result, err := connector.SyncFromCheckpoint(c, cfg, ctx)
+		logging.FromContext(ctx).Infof("Indexed %d %s items, %d failed", result.Indexed, source, result.Failed)
+
+		return err
+	}
+}
+
+// registerJobHandlers shows the last runs of the scheduled jobs and lets admins run a job right away.
+func registerJobHandlers(mux *http.ServeMux, jobs *scheduler.Scheduler, cfg config.Config, logger *zap.SugaredLogger, middleware func(http.Handler) http.Handler) {
+	mux.Handle("/api/v1/admin/jobs", middleware(requireAdminApiKey(cfg, logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			JSONError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed, logger)
+			return
+		}
+
+		JSONResp(w, jobs.Overview(), logger)
+	}))))
+
+	mux.Handle("/api/v1/admin/jobs/", middleware(requireAdminApiKey(cfg, logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, action, found := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/jobs/"), "/")
+		if !found || name == "" || action != "run" {
+			JSONError(w, errors.New("not found"), http.StatusNotFound, logger)
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			JSONError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed, logger)
+			return
+		}
+
+		err := jobs.Trigger(name)
+		if errors.Is(err, scheduler.ErrUnknownJob) {
+			JSONError(w, err, http.StatusNotFound, logger)
+			return
+		}
+
+		if err != nil {
+			JSONError(w, err, http.StatusConflict, logger)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}))))
+}
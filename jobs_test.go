@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+
+	"go.uber.org/zap"
+)
+
+func TestNewSchedulerOnlySyncsTheForumWithUrl(t *testing.T) {
+	jobNames := func(cfg config.Config) string {
+		var names []string
+		for _, job := range newScheduler(cfg, zap.NewNop().Sugar()).Overview().Jobs {
+			names = append(names, job.Name)
+		}
+
+		return strings.Join(names, ",")
+	}
+
+	if names := jobNames(config.Config{SchedulerEnabled: true, DiscourseSyncInterval: time.Hour}); strings.Contains(names, "discourse-sync") {
+		t.Errorf("expected no forum job without DISCOURSE_URL, got %s", names)
+	}
+
+	if names := jobNames(config.Config{SchedulerEnabled: true, DiscourseUrl: "https://forum.example.com", DiscourseSyncInterval: time.Hour}); !strings.Contains(names, "discourse-sync") {
+		t.Errorf("expected a forum job with DISCOURSE_URL, got %s", names)
+	}
+
+	if names := jobNames(config.Config{DiscourseUrl: "https://forum.example.com", DiscourseSyncInterval: time.Hour}); names != "" {
+		t.Errorf("expected no jobs without SCHEDULER_ENABLED, got %s", names)
+	}
+}
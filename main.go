@@ -4,12 +4,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/shopwarelabs/jira-issue-bot/domain/connector"
+	"github.com/shopwarelabs/jira-issue-bot/domain/discourse_connector"
+	"github.com/shopwarelabs/jira-issue-bot/domain/github_connector"
+	"github.com/shopwarelabs/jira-issue-bot/domain/stack_overflow_connector"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/cmd"
-	"github.com/shopwarelabs/jira-issue-bot/infrastructure/cmd/github_cmd"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/cmd/connector_cmd"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/cmd/stack_overflow_cmd"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/tracing"
 
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/spf13/cobra"
@@ -30,7 +37,16 @@ var indexCommand = &cobra.Command{
 	Short: "Index issues from Platforms",
 }
 
+func init() {
+	connector.Register(github_connector.Connector{}, github_connector.DiscussionsConnector{}, stack_overflow_connector.Connector{}, discourse_connector.Connector{})
+}
+
 func main() {
+	os.Exit(run())
+}
+
+// run returns the exit code of the command, so the deferred flushes run before os.Exit.
+func run() int {
 	if fileExists(".env") {
 		_ = gotenv.Load(".env")
 	} else if fileExists(".env.dist") {
@@ -42,6 +58,10 @@ func main() {
 		ctx = context.Background()
 	}
 
+	// Ctrl-C and SIGTERM cancel the context, so in-flight OpenSearch and GitHub calls are aborted.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	cfg, err := config.NewFromEnv(ctx)
 	if err != nil {
 		panic(fmt.Sprintf("Error loading config from env: %v\n", err))
@@ -52,18 +72,32 @@ func main() {
 	defer logger.Sync() //nolint:errcheck
 	ctx = logging.WithLogger(ctx, logger)
 
+	shutdownTracing, err := tracing.Setup(cfg.TracesExporter, ctx)
+	if err != nil {
+		panic(fmt.Sprintf("Error setting up tracing: %v\n", err))
+	}
+
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Errorf("Error flushing traces: %s", err)
+		}
+	}()
+
 	rootCmd.SetContext(context.WithValue(ctx, cmd.ConfigKey{}, cfg))
 
 	rootCmd.AddCommand(downloadCommand)
 	rootCmd.AddCommand(indexCommand)
 	rootCmd.AddCommand(serverCommand)
 	cmd.Register(rootCmd)
-	github_cmd.Register(rootCmd, downloadCommand, indexCommand)
-	stack_overflow_cmd.Register(rootCmd, downloadCommand, indexCommand)
+	connector_cmd.Register(rootCmd, downloadCommand, indexCommand)
+	stack_overflow_cmd.Register(rootCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		logger.Error(err)
+		return 1
 	}
+
+	return 0
 }
 
 func fileExists(filename string) bool {
@@ -3,22 +3,30 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
-	"os"
+	"sync"
 	"time"
 
-	"github.com/shopwarelabs/jira-issue-bot/domain/github_connector"
+	"github.com/shopwarelabs/jira-issue-bot/api"
+	"github.com/shopwarelabs/jira-issue-bot/domain/connector"
 	"github.com/shopwarelabs/jira-issue-bot/domain/slack_connector"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/cmd"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/health"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/scheduler"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/tracing"
 
 	"github.com/MadAppGang/httplog"
-	"github.com/google/go-github/v50/github"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -26,58 +34,110 @@ var serverCommand = &cobra.Command{
 	Use:   "server",
 	Short: "Start the server",
 	RunE: func(command *cobra.Command, args []string) error {
-		cfg := command.Context().Value(cmd.ConfigKey{}).(config.Config)
+		ctx := command.Context()
+		cfg := ctx.Value(cmd.ConfigKey{}).(config.Config)
+		logger := logging.FromContext(ctx)
 
-		server := createServer(cfg, command.Context())
+		// ctx is cancelled on SIGINT / SIGTERM. Requests and Slack events which are already being handled
+		// continue on workCtx, which is only cancelled when they did not finish within the shutdown timeout.
+		workCtx, cancelWork := context.WithCancel(logging.WithLogger(context.Background(), logger))
+		defer cancelWork()
 
-		return server.ListenAndServe()
-	},
-}
+		jobs := newScheduler(cfg, logger)
 
-func createServer(cfg config.Config, context context.Context) *http.Server {
-	logger := logging.FromContext(context)
-	loggerWithFormatter := httplog.LoggerWithFormatter(httplog.DefaultLogFormatter)
+		server := createServer(cfg, jobs, workCtx)
+		server.BaseContext = func(net.Listener) context.Context {
+			return workCtx
+		}
+
+		var work sync.WaitGroup
+
+		// Running jobs are cancelled on shutdown, a sync keeps its checkpoint and continues on the next run.
+		work.Add(1)
+		go func() {
+			defer work.Done()
+			jobs.Run(ctx)
+		}()
 
-	http.Handle("/webhook/github", loggerWithFormatter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var payload []byte
-		var err error
+		if cfg.SlackSocketMode {
+			work.Add(1)
 
-		if os.Getenv("CI") == "true" {
-			payload, err = io.ReadAll(r.Body)
-		} else {
-			payload, err = github.ValidatePayload(r, []byte(cfg.GithubWebhookSecret))
+			go func() {
+				defer work.Done()
+
+				if err := runSocketMode(cfg, ctx, workCtx); err != nil && !errors.Is(err, context.Canceled) {
+					logger.Errorf("Slack socket mode stopped: %s", err)
+				}
+			}()
 		}
 
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
+		serverErr := make(chan error, 1)
+		go func() {
+			serverErr <- listen(server, cfg)
+		}()
+
+		logger.Infof("Listening on %s", cfg.ServerAddr)
+
+		select {
+		case err := <-serverErr:
+			return err
+		case <-ctx.Done():
 		}
 
-		event, err := github.ParseWebHook(github.WebHookType(r), payload)
+		logger.Infof("Shutting down, waiting up to %s for in-flight work", cfg.ServerShutdownTimeout)
 
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ServerShutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down server: %w", err)
 		}
 
-		switch event := event.(type) {
-		case *github.IssuesEvent:
-			if err = github_connector.HandleGithubIssueEvent(event, cfg, logger); err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				return
-			}
-		case *github.PullRequestEvent:
-			if err = github_connector.HandleGithubPREvent(event, cfg, logger); err != nil {
-				w.WriteHeader(http.StatusBadRequest)
-				return
-			}
-		default:
-			w.WriteHeader(http.StatusBadRequest)
-			return
+		drained := make(chan struct{})
+		go func() {
+			work.Wait()
+			slackWork.Wait()
+			close(drained)
+		}()
+
+		select {
+		case <-drained:
+		case <-shutdownCtx.Done():
+			return fmt.Errorf("failed to finish Slack events: %w", shutdownCtx.Err())
+		}
+
+		return nil
+	},
+}
+
+// listen serves HTTPS when a certificate is configured, HTTP otherwise.
+func listen(server *http.Server, cfg config.Config) error {
+	var err error
+	if cfg.ServerTLSCertFile != "" {
+		err = server.ListenAndServeTLS(cfg.ServerTLSCertFile, cfg.ServerTLSKeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+func createServer(cfg config.Config, jobs *scheduler.Scheduler, workCtx context.Context) *http.Server {
+	logger := logging.FromContext(workCtx)
+	loggerWithFormatter := httplog.LoggerWithFormatter(httplog.DefaultLogFormatter)
+	mux := http.NewServeMux()
+
+	for _, source := range connector.All() {
+		if webhook, ok := source.(connector.WebhookConnector); ok {
+			mux.Handle("/webhook/"+source.Name(), loggerWithFormatter(metrics.InstrumentWebhook(source.Name(), webhook.WebhookEvent, webhook.Webhook(cfg))))
 		}
-	})))
+	}
 
-	http.Handle("/slack/command", loggerWithFormatter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/slack/command", loggerWithFormatter(metrics.InstrumentWebhook("slack", slackEvent("command"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		verifier, err := slack.NewSecretsVerifier(r.Header, cfg.SlackSigningSecret)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -90,23 +150,52 @@ func createServer(cfg config.Config, context context.Context) *http.Server {
 			return
 		}
 
-		switch command.Command {
-		case "/issues":
-		case "/aiaiai":
-			message, err := slack_connector.OnIssuesCommand(command, cfg, logger)
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-			JSONResp(w, message, logger)
+		// The answer is posted to the response_url of the request, so it has to come from Slack.
+		if err := verifier.Ensure(); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
 			return
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		metrics.WebhookValidated(r.Context())
+
+		// The answer is posted to the response_url of the command, Slack only waits 3 seconds for this response.
+		handleSlackAsync(workCtx, "slack.command", []attribute.KeyValue{attribute.String("slack.command", command.Command)}, func(ctx context.Context) error {
+			return slack_connector.RespondToSlashCommand(command, cfg, ctx)
+		})
+	}))))
+
+	mux.Handle("/slack/interaction", loggerWithFormatter(metrics.InstrumentWebhook("slack", slackEvent("interaction"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifier, err := slack.NewSecretsVerifier(r.Header, cfg.SlackSigningSecret)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(io.TeeReader(r.Body, &verifier))
+
+		payload := r.FormValue("payload")
+		if err := verifier.Ensure(); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		metrics.WebhookValidated(r.Context())
+
+		var callback slack.InteractionCallback
+		if err := json.Unmarshal([]byte(payload), &callback); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-	})))
 
-	http.Handle("/slack/event", loggerWithFormatter(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if response := slack_connector.InteractionAck(callback); response != nil {
+			JSONResp(w, response, logger)
+		}
+
+		handleSlackAsync(workCtx, "slack.interaction", []attribute.KeyValue{attribute.String("slack.interaction", string(callback.Type))}, func(ctx context.Context) error {
+			return slack_connector.OnInteraction(callback, cfg, ctx)
+		})
+	}))))
+
+	mux.Handle("/slack/event", loggerWithFormatter(metrics.InstrumentWebhook("slack", slackEvent("event"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		body, err := io.ReadAll(r.Body)
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
@@ -128,6 +217,8 @@ func createServer(cfg config.Config, context context.Context) *http.Server {
 			return
 		}
 
+		metrics.WebhookValidated(r.Context())
+
 		eventsAPIEvent, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -145,27 +236,50 @@ func createServer(cfg config.Config, context context.Context) *http.Server {
 			_, _ = w.Write([]byte(r.Challenge))
 		}
 
+		// Slack retries events which are not acknowledged within 3 seconds, searching can take longer than that.
 		if eventsAPIEvent.Type == slackevents.CallbackEvent {
-			innerEvent := eventsAPIEvent.InnerEvent
-			mentionEvent, ok := innerEvent.Data.(*slackevents.AppMentionEvent)
-			if !ok {
-				w.WriteHeader(http.StatusBadRequest)
-				return
-			}
+			handleSlackAsync(workCtx, "slack.event", []attribute.KeyValue{attribute.String("slack.event", eventsAPIEvent.InnerEvent.Type)}, func(ctx context.Context) error {
+				err := slack_connector.OnEvent(eventsAPIEvent.InnerEvent, cfg, ctx)
 
-			err := slack_connector.OnMention(mentionEvent, cfg, logger)
+				if errors.Is(err, slack_connector.ErrUnsupportedEvent) {
+					logging.FromContext(ctx).Debugf("Ignoring unsupported event: %s", eventsAPIEvent.InnerEvent.Type)
 
-			if err != nil {
-				_, _ = w.Write([]byte(err.Error()))
-				w.WriteHeader(http.StatusBadRequest)
-				return
-			}
+					return nil
+				}
+
+				return err
+			})
 		}
-	})))
+	}))))
+
+	registerApiHandlers(mux, cfg, logger, loggerWithFormatter)
+	registerJudgmentHandlers(mux, cfg, logger, loggerWithFormatter)
+	registerJobHandlers(mux, jobs, cfg, logger, loggerWithFormatter)
+
+	mux.Handle("/app/", uiHandler())
+
+	registerHealthHandlers(mux, health.NewChecker(cfg, logger), logger)
+
+	mux.Handle("/metrics", metrics.Handler())
+
+	mux.HandleFunc("/api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write(api.OpenAPI)
+	})
 
 	return &http.Server{
-		Addr:              ":8000",
+		Addr:              cfg.ServerAddr,
+		Handler:           tracing.Handler(mux, workCtx),
 		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       cfg.ServerReadTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+	}
+}
+
+// slackEvent labels the metrics of a Slack endpoint, the payload is only parsed by the handler itself.
+func slackEvent(name string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		return name
 	}
 }
 
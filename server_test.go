@@ -12,8 +12,10 @@ import (
 
 	"github.com/shopwarelabs/jira-issue-bot/domain/search"
 	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/scheduler"
 
 	"github.com/steinfletcher/apitest"
+	"go.uber.org/zap"
 )
 
 // Not possible to split into multiple tests, as by default go executes the tests in parallel
@@ -21,10 +23,10 @@ func TestServerIntegration(t *testing.T) {
 	ctx := context.Background()
 	cfg, _ := config.NewFromEnv(ctx)
 
-	createServer(cfg, ctx)
+	server := createServer(cfg, scheduler.New(nil, 0, 0, zap.NewNop().Sugar()), ctx)
 
 	// test UI serving
-	apitest.New().Handler(http.DefaultServeMux).
+	apitest.New().Handler(server.Handler).
 		Get("/app/").
 		Expect(t).
 		Status(http.StatusOK).
@@ -32,7 +34,7 @@ func TestServerIntegration(t *testing.T) {
 		End()
 
 	// index one jira issue
-	apitest.New().Handler(http.DefaultServeMux).
+	apitest.New().Handler(server.Handler).
 		Post("/webhook/jira").
 		Body(`{
 			"webhookEvent": "jira:issue_updated",
@@ -61,7 +63,7 @@ func TestServerIntegration(t *testing.T) {
 		End()
 
 	// index one github issue
-	apitest.New().Handler(http.DefaultServeMux).
+	apitest.New().Handler(server.Handler).
 		Post("/webhook/github").
 		Header("X-GitHub-Event", "issues").
 		Body(`{
@@ -90,7 +92,7 @@ func TestServerIntegration(t *testing.T) {
 	time.Sleep(1 * time.Second)
 
 	// search per API
-	searchResult := apitest.New().Handler(http.DefaultServeMux).
+	searchResult := apitest.New().Handler(server.Handler).
 		Post("/api/search").
 		Query("title", "Fancy github webhook").
 		Expect(t).
@@ -121,7 +123,7 @@ func TestServerIntegration(t *testing.T) {
 	}
 
 	// index duplicate to first jira issue
-	apitest.New().Handler(http.DefaultServeMux).
+	apitest.New().Handler(server.Handler).
 		Post("/webhook/jira").
 		Body(`{
 			"webhookEvent": "jira:issue_updated",
@@ -153,7 +155,7 @@ func TestServerIntegration(t *testing.T) {
 	time.Sleep(1 * time.Second)
 
 	// search per ID
-	searchResult = apitest.New().Handler(http.DefaultServeMux).
+	searchResult = apitest.New().Handler(server.Handler).
 		Post("/api/search-id").
 		Query("id", "NEXT-1").
 		Expect(t).
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// slackWork tracks the Slack events which are handled after they were acknowledged, the server waits for them on
+// shutdown.
+var slackWork sync.WaitGroup
+
+// handleSlackAsync runs handle in the background, Slack expects an acknowledgement within 3 seconds and retries
+// the event otherwise. Errors are logged, as there is no request left to fail.
+func handleSlackAsync(ctx context.Context, span string, attributes []attribute.KeyValue, handle func(ctx context.Context) error) {
+	slackWork.Add(1)
+
+	go func() {
+		defer slackWork.Done()
+
+		ctx, span := tracing.Tracer().Start(ctx, span, trace.WithAttributes(attributes...))
+		defer span.End()
+
+		if err := handle(ctx); err != nil {
+			span.RecordError(err)
+			logging.FromContext(ctx).Errorf("Error while handling Slack request: %s", err)
+		}
+	}()
+}
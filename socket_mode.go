@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopwarelabs/jira-issue-bot/domain/slack_connector"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/config"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/logging"
+	"github.com/shopwarelabs/jira-issue-bot/infrastructure/metrics"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// runSocketMode receives Slack events over a websocket instead of the public /slack/* endpoints,
+// so the bot can run without being reachable from the internet. The connection is closed when ctx
+// is cancelled. Every event is acknowledged right away and handled in the background with handlerCtx,
+// the server waits for these handlers on shutdown, see slackWork.
+func runSocketMode(cfg config.Config, ctx context.Context, handlerCtx context.Context) error {
+	logger := logging.FromContext(ctx)
+
+	if cfg.SlackAppToken == "" {
+		return fmt.Errorf("SLACK_APP_TOKEN is required for socket mode")
+	}
+
+	api := slack.New(cfg.SlackBotToken, slack.OptionAppLevelToken(cfg.SlackAppToken), slack.OptionHTTPClient(metrics.HTTPClient("slack")))
+	client := socketmode.New(api)
+
+	// The loop also has to stop when the connection fails for good.
+	loopCtx, stopLoop := context.WithCancel(ctx)
+	handled := make(chan struct{})
+
+	go func() {
+		defer close(handled)
+
+		for {
+			var evt socketmode.Event
+
+			select {
+			case <-loopCtx.Done():
+				return
+			case evt = <-client.Events:
+			}
+
+			switch evt.Type {
+			case socketmode.EventTypeConnecting:
+				logger.Debug("Connecting to Slack with socket mode")
+			case socketmode.EventTypeConnectionError:
+				logger.Errorf("Slack socket mode connection failed: %v", evt.Data)
+			case socketmode.EventTypeConnected:
+				logger.Info("Connected to Slack with socket mode")
+			case socketmode.EventTypeEventsAPI:
+				eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+				if !ok {
+					continue
+				}
+				client.Ack(*evt.Request)
+
+				handleSlackAsync(handlerCtx, "slack.socket_mode.event", []attribute.KeyValue{attribute.String("slack.event", eventsAPIEvent.InnerEvent.Type)}, func(ctx context.Context) error {
+					err := slack_connector.OnEvent(eventsAPIEvent.InnerEvent, cfg, ctx)
+
+					if errors.Is(err, slack_connector.ErrUnsupportedEvent) {
+						logger.Debugf("Ignoring unsupported event: %s", eventsAPIEvent.InnerEvent.Type)
+						metrics.WebhookEvents.WithLabelValues("slack", "event", "rejected").Inc()
+
+						return nil
+					}
+
+					metrics.WebhookEvents.WithLabelValues("slack", "event", metrics.Outcome(err)).Inc()
+
+					return err
+				})
+			case socketmode.EventTypeSlashCommand:
+				command, ok := evt.Data.(slack.SlashCommand)
+				if !ok {
+					continue
+				}
+				client.Ack(*evt.Request)
+
+				handleSlackAsync(handlerCtx, "slack.socket_mode.command", []attribute.KeyValue{attribute.String("slack.command", command.Command)}, func(ctx context.Context) error {
+					err := slack_connector.RespondToSlashCommand(command, cfg, ctx)
+					metrics.WebhookEvents.WithLabelValues("slack", "command", metrics.Outcome(err)).Inc()
+
+					return err
+				})
+			case socketmode.EventTypeInteractive:
+				callback, ok := evt.Data.(slack.InteractionCallback)
+				if !ok {
+					continue
+				}
+
+				if response := slack_connector.InteractionAck(callback); response != nil {
+					client.Ack(*evt.Request, response)
+				} else {
+					client.Ack(*evt.Request)
+				}
+
+				handleSlackAsync(handlerCtx, "slack.socket_mode.interaction", []attribute.KeyValue{attribute.String("slack.interaction", string(callback.Type))}, func(ctx context.Context) error {
+					err := slack_connector.OnInteraction(callback, cfg, ctx)
+					metrics.WebhookEvents.WithLabelValues("slack", "interaction", metrics.Outcome(err)).Inc()
+
+					return err
+				})
+			default:
+				logger.Debugf("Ignoring socket mode event: %s", evt.Type)
+			}
+		}
+	}()
+
+	err := client.RunContext(ctx)
+
+	stopLoop()
+	<-handled
+
+	return err
+}
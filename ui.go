@@ -0,0 +1,20 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed ui/dist
+var uiFiles embed.FS
+
+// uiHandler serves the web UI for searching and triaging duplicates, it talks to the /api/v1 endpoints.
+func uiHandler() http.Handler {
+	dist, err := fs.Sub(uiFiles, "ui/dist")
+	if err != nil {
+		panic(err)
+	}
+
+	return http.StripPrefix("/app/", http.FileServer(http.FS(dist)))
+}
@@ -0,0 +1,126 @@
+(function () {
+    'use strict';
+
+    const form = document.getElementById('search-form');
+    const apiKeyInput = document.getElementById('api-key');
+    const message = document.getElementById('message');
+    const results = document.getElementById('results');
+    const template = document.getElementById('result-template');
+
+    apiKeyInput.value = localStorage.getItem('apiKey') || '';
+    apiKeyInput.addEventListener('change', () => localStorage.setItem('apiKey', apiKeyInput.value));
+
+    form.querySelectorAll('input[name=mode]').forEach((radio) => {
+        radio.addEventListener('change', () => {
+            const byDocument = form.mode.value === 'document';
+            form.querySelector('.mode-text').hidden = byDocument;
+            form.querySelector('.mode-document').hidden = !byDocument;
+        });
+    });
+
+    form.addEventListener('submit', async (event) => {
+        event.preventDefault();
+
+        const params = new URLSearchParams({ explain: 'true' });
+        const sources = [...form.querySelectorAll('input[name=sources]:checked')].map((input) => input.value);
+        const labels = form.labels.value.split(',').map((label) => label.trim()).filter(Boolean);
+
+        if (sources.length) params.set('sources', sources.join(','));
+        if (labels.length) params.set('labels', labels.join(','));
+        if (form.status.value) params.set('status', form.status.value);
+        if (form.minScore.value) params.set('minScore', form.minScore.value);
+
+        let url;
+        let subject;
+        if (form.mode.value === 'document') {
+            subject = { documentId: form.documentId.value.trim() };
+            url = `/api/v1/documents/${encodeURIComponent(subject.documentId)}/similar?${params}`;
+        } else {
+            subject = { query: [form.title.value, form.description.value].filter(Boolean).join('\n') };
+            params.set('title', form.title.value);
+            params.set('description', form.description.value);
+            url = `/api/v1/search?${params}`;
+        }
+
+        message.textContent = 'Searching…';
+        results.replaceChildren();
+
+        try {
+            const response = await request(url);
+            const hits = response.hits.hits;
+
+            message.textContent = hits.length ? `${hits.length} results` : 'No results';
+            const maxScore = Math.max(...hits.map((hit) => hit._score), 1);
+            hits.forEach((hit) => results.appendChild(renderHit(hit, subject, maxScore)));
+        } catch (error) {
+            message.textContent = error.message;
+        }
+    });
+
+    function renderHit(hit, subject, maxScore) {
+        const item = template.content.firstElementChild.cloneNode(true);
+        const title = item.querySelector('.title');
+
+        title.textContent = `${hit._id}: ${hit._source.title}`;
+        title.href = hit._source.link;
+        item.querySelector('.score').textContent = hit._score.toFixed(2);
+        item.querySelector('.meta').textContent = [
+            hit._source.source,
+            hit._source.type,
+            hit._source.status,
+            (hit._source.labels || []).join(', '),
+        ].filter(Boolean).join(' · ');
+
+        const breakdown = item.querySelector('.breakdown');
+        Object.entries(hit.breakdown || {}).forEach(([field, score]) => {
+            const bar = document.createElement('div');
+            const label = document.createElement('span');
+            const meter = document.createElement('meter');
+            const value = document.createElement('span');
+
+            bar.className = 'bar';
+            label.textContent = field;
+            meter.max = maxScore;
+            meter.value = score;
+            value.textContent = score.toFixed(2);
+            bar.append(label, meter, value);
+            breakdown.appendChild(bar);
+        });
+
+        const status = item.querySelector('.judgment');
+        const judge = async (duplicate) => {
+            try {
+                await request('/api/v1/judgments', {
+                    method: 'POST',
+                    body: JSON.stringify({ ...subject, candidateId: hit._id, duplicate, score: hit._score }),
+                });
+                status.textContent = duplicate ? 'Marked as duplicate' : 'Marked as not a duplicate';
+            } catch (error) {
+                status.textContent = error.message;
+            }
+        };
+
+        item.querySelector('.duplicate').addEventListener('click', () => judge(true));
+        item.querySelector('.not-duplicate').addEventListener('click', () => judge(false));
+
+        return item;
+    }
+
+    async function request(url, options = {}) {
+        const response = await fetch(url, {
+            ...options,
+            headers: { 'Content-Type': 'application/json', 'X-Api-Key': apiKeyInput.value },
+        });
+
+        if (response.status === 201) {
+            return null;
+        }
+
+        const body = await response.json();
+        if (!response.ok) {
+            throw new Error(body.message || `Request failed with status ${response.status}`);
+        }
+
+        return body;
+    }
+}());
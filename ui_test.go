@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestUiHandlerServesIndex(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	uiHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/app/", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	expected, err := os.ReadFile("ui/dist/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if recorder.Body.String() != string(expected) {
+		t.Error("expected /app/ to serve ui/dist/index.html")
+	}
+}